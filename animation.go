@@ -0,0 +1,134 @@
+package tiled
+
+import (
+	"image"
+	"time"
+)
+
+// Animation is a timeline of frames.
+type Animation struct {
+	Frames []Frame
+	Mode   AnimationMode
+}
+
+// Frame is a frame of an animation.
+type Frame struct {
+	Image image.Image
+	// Duration is how long the frame is shown.
+	Duration time.Duration
+	// Event is name of the event triggered when the frame starts. eg. "hit"
+	// It is empty for frames without an event.
+	Event string
+}
+
+// AnimationMode decides what an animation does after its last frame.
+type AnimationMode int
+
+const (
+	// AnimateOnce stays at the last frame.
+	AnimateOnce = AnimationMode(iota)
+	// AnimateLoop goes back to the first frame.
+	AnimateLoop
+)
+
+// Length returns sum of durations of the frames.
+func (a *Animation) Length() time.Duration {
+	var l time.Duration
+	for _, f := range a.Frames {
+		l += f.Duration
+	}
+	return l
+}
+
+// FrameAt returns index of the frame shown at the age.
+// It returns -1 if the animation doesn't have a frame.
+func (a *Animation) FrameAt(age time.Duration) int {
+	l := a.Length()
+	if l <= 0 {
+		return len(a.Frames) - 1
+	}
+	if a.Mode == AnimateLoop {
+		age %= l
+	}
+	for i, f := range a.Frames {
+		if age < f.Duration {
+			return i
+		}
+		age -= f.Duration
+	}
+	return len(a.Frames) - 1
+}
+
+// Events returns events of the frames starting in [from, to), in order.
+func (a *Animation) Events(from, to time.Duration) []string {
+	l := a.Length()
+	if l <= 0 {
+		return nil
+	}
+	events := make([]string, 0)
+	var base time.Duration
+	if a.Mode == AnimateLoop {
+		base = from / l * l
+	}
+	for ; base < to; base += l {
+		t := base
+		for _, f := range a.Frames {
+			if f.Event != "" && t >= from && t < to {
+				events = append(events, f.Event)
+			}
+			t += f.Duration
+		}
+		if a.Mode != AnimateLoop {
+			break
+		}
+	}
+	return events
+}
+
+// lifetime returns how long the action lasts. Zero means it lasts until interrupted.
+// An action without Lifetime lasts as long as its animation, unless the animation loops.
+func (a *Action) lifetime() time.Duration {
+	if a.Lifetime != 0 {
+		return a.Lifetime
+	}
+	if a.Animation.Mode == AnimateOnce {
+		return a.Animation.Length()
+	}
+	return 0
+}
+
+// advance passes time of the action by d, triggering frame events on the character.
+// When the action is finished, it reports true with time left after the action.
+func (a *Action) advance(c *Character, d time.Duration) (time.Duration, bool) {
+	from, to := a.Age, a.Age+d
+	life := a.lifetime()
+	finished := life != 0 && to >= life
+	if finished {
+		to = life
+	}
+	for _, ev := range a.Animation.Events(from, to) {
+		if fn := a.Events[ev]; fn != nil {
+			fn(c)
+		}
+		if ev == a.resolveOn {
+			a.resolve()
+		}
+	}
+	a.Age = to
+	if finished {
+		// events are not lost even if the frame is never shown.
+		a.resolve()
+		return from + d - life, true
+	}
+	return 0, false
+}
+
+// resolve resolves events queued on the action, if any.
+func (a *Action) resolve() {
+	evs := a.resolving
+	if len(evs) == 0 {
+		return
+	}
+	a.resolving = nil
+	a.stage.Resolve(evs)
+}
@@ -0,0 +1,144 @@
+package tiled
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAnimation(t *testing.T) {
+	ms := time.Millisecond
+	a := &Animation{
+		Frames: []Frame{
+			{Duration: 100 * ms, Event: "start"},
+			{Duration: 50 * ms},
+			{Duration: 50 * ms, Event: "hit"},
+		},
+	}
+	if a.Length() != 200*ms {
+		t.Fatalf("length: want 200ms, got %v", a.Length())
+	}
+	cases := []struct {
+		age  time.Duration
+		once int
+		loop int
+	}{
+		{0, 0, 0},
+		{99 * ms, 0, 0},
+		{100 * ms, 1, 1},
+		{170 * ms, 2, 2},
+		{250 * ms, 2, 0},
+		{360 * ms, 2, 2},
+	}
+	for _, c := range cases {
+		a.Mode = AnimateOnce
+		if got := a.FrameAt(c.age); got != c.once {
+			t.Fatalf("once frame at %v: want %v, got %v", c.age, c.once, got)
+		}
+		a.Mode = AnimateLoop
+		if got := a.FrameAt(c.age); got != c.loop {
+			t.Fatalf("loop frame at %v: want %v, got %v", c.age, c.loop, got)
+		}
+	}
+	a.Mode = AnimateOnce
+	if got := a.Events(0, 500*ms); !reflect.DeepEqual(got, []string{"start", "hit"}) {
+		t.Fatalf("once events: want [start hit], got %v", got)
+	}
+	a.Mode = AnimateLoop
+	if got := a.Events(150*ms, 450*ms); !reflect.DeepEqual(got, []string{"hit", "start", "hit", "start"}) {
+		t.Fatalf("loop events: want [hit start hit start], got %v", got)
+	}
+}
+
+func TestTickActions(t *testing.T) {
+	ms := time.Millisecond
+	c := &Character{HP: 10}
+	rest := &Action{Name: "rest", Animation: Animation{
+		Mode:   AnimateLoop,
+		Frames: []Frame{{Duration: 100 * ms}, {Duration: 100 * ms}},
+	}}
+	attack := &Action{Name: "attack", Animation: Animation{
+		Frames: []Frame{{Duration: 100 * ms}, {Duration: 100 * ms, Event: "hit"}, {Duration: 100 * ms}},
+	}}
+	attack.Events = map[string]func(c *Character){
+		"hit": func(c *Character) { c.HP -= 3 },
+	}
+	c.RestAction = rest
+	c.Tick(150 * ms)
+	if c.PendingActions[0] != rest || rest.Age != 150*ms {
+		t.Fatalf("should rest while nothing is pending")
+	}
+	c.PendingActions = append(c.PendingActions, attack)
+	c.Tick(50 * ms)
+	if c.PendingActions[0] != attack || c.HP != 10 {
+		t.Fatalf("attack should interrupt rest, and not hit yet")
+	}
+	c.Tick(100 * ms)
+	if c.HP != 7 {
+		t.Fatalf("hit frame should trigger the event: want hp 7, got %v", c.HP)
+	}
+	c.Tick(200 * ms)
+	if c.PendingActions[0] != rest || rest.Age != 50*ms || c.HP != 7 {
+		t.Fatalf("left time should go to the rest action, got %v %v", c.PendingActions[0].Name, rest.Age)
+	}
+}
+
+// hitSkill hits the target by 4, at the hit frame of its action.
+type hitSkill struct {
+	caster, target *Character
+}
+
+func (sk hitSkill) Origin() *Tile        { return sk.caster.Tile() }
+func (sk hitSkill) SelectableArea() Area { return CreateArea([]Pos{{1, 0}}) }
+func (sk hitSkill) CastArea(sel Pos) Area {
+	return CreateArea([]Pos{sel})
+}
+func (sk hitSkill) Cast(sel Pos) []CharacterEvent {
+	return []CharacterEvent{{Character: sk.target, Source: sk.caster, On: "attacked", Value: 4, Effect: func(ev *CharacterEvent) {
+		ev.Character.HP -= ev.Value
+	}}}
+}
+func (sk hitSkill) Action() *Action {
+	return &Action{Name: "attack", Animation: Animation{
+		Frames: []Frame{{Duration: 100 * time.Millisecond}, {Duration: 100 * time.Millisecond, Event: HitFrame}},
+	}}
+}
+
+func TestAnimatedSkill(t *testing.T) {
+	ms := time.Millisecond
+	b := newTestBoard([]string{".."})
+	a, e := newTestParty(10), newTestParty(10)
+	c, en := a.Characters[0], e.Characters[0]
+	c.Place(b.TileAt[Pos{0, 0}])
+	en.Place(b.TileAt[Pos{1, 0}])
+	s := &Stage{Board: b, Parties: []*Party{a, e}}
+	damaged := 0
+	s.Events.Subscribe(func(ev Event) {
+		if _, ok := ev.(Damaged); ok {
+			damaged++
+		}
+	})
+	sk := hitSkill{caster: c, target: en}
+	if !s.Cast(sk, Pos{1, 0}) || en.HP != 10 || len(c.PendingActions) != 1 {
+		t.Fatalf("cast: want the action pending and hp 10, got %v actions and hp %v", len(c.PendingActions), en.HP)
+	}
+	c.Tick(50 * ms)
+	if en.HP != 10 {
+		t.Fatalf("before the hit frame: want hp 10, got %v", en.HP)
+	}
+	c.Tick(60 * ms)
+	if en.HP != 6 || damaged != 1 {
+		t.Fatalf("at the hit frame: want hp 6 and a damaged event, got %v and %v", en.HP, damaged)
+	}
+	s.Undo()
+	if en.HP != 10 || len(c.PendingActions) != 0 {
+		t.Fatalf("undo: want hp 10 without pending actions, got %v and %v", en.HP, len(c.PendingActions))
+	}
+	if !s.Redo() || en.HP != 10 || len(c.PendingActions) != 1 {
+		t.Fatalf("redo: want the action pending again and hp 10, got %v actions and hp %v", len(c.PendingActions), en.HP)
+	}
+	c.Tick(500 * ms)
+	if en.HP != 6 {
+		t.Fatalf("hit after redo: want hp 6, got %v", en.HP)
+	}
+}
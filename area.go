@@ -0,0 +1,133 @@
+package tiled
+
+import (
+	"sort"
+)
+
+// Area is composed with Poses in tiled world.
+// Adding an existing Pos do nothing to the area.
+// Removing a non-existing Pos do nothing as well.
+// Methods of Area don't change the area, but return a new one.
+type Area struct {
+	pos map[Pos]bool
+}
+
+func CreateArea(poses []Pos) Area {
+	a := Area{pos: make(map[Pos]bool)}
+	for _, p := range poses {
+		a.pos[p] = true
+	}
+	return a
+}
+
+func (a Area) Add(poses []Pos) Area {
+	n := a.copy()
+	for _, p := range poses {
+		n.pos[p] = true
+	}
+	return n
+}
+
+func (a Area) Sub(poses []Pos) Area {
+	n := a.copy()
+	for _, p := range poses {
+		delete(n.pos, p)
+	}
+	return n
+}
+
+func (a Area) copy() Area {
+	n := Area{pos: make(map[Pos]bool, len(a.pos))}
+	for p := range a.pos {
+		n.pos[p] = true
+	}
+	return n
+}
+
+// Len returns number of poses in the area.
+func (a Area) Len() int {
+	return len(a.pos)
+}
+
+func (a Area) Contains(p Pos) bool {
+	return a.pos[p]
+}
+
+func (a Area) Union(b Area) Area {
+	n := a.copy()
+	for p := range b.pos {
+		n.pos[p] = true
+	}
+	return n
+}
+
+func (a Area) Intersect(b Area) Area {
+	n := Area{pos: make(map[Pos]bool)}
+	for p := range a.pos {
+		if b.pos[p] {
+			n.pos[p] = true
+		}
+	}
+	return n
+}
+
+func (a Area) Difference(b Area) Area {
+	n := Area{pos: make(map[Pos]bool)}
+	for p := range a.pos {
+		if !b.pos[p] {
+			n.pos[p] = true
+		}
+	}
+	return n
+}
+
+// Translate moves the area as {0, 0} goes to origin.
+// It makes an area relative to a tile, eg. a skill's area, to the board positions.
+func (a Area) Translate(origin Pos) Area {
+	return a.Map(func(p Pos) Pos {
+		return p.Add(origin)
+	})
+}
+
+// Rotate rotates the area around {0, 0} by n steps of the board clockwise.
+// A step is 90 degrees on quad boards, and 60 degrees on hex boards.
+// Negative n rotates the area counter clockwise.
+func (a Area) Rotate(b *Board, n int) Area {
+	return a.Map(func(p Pos) Pos {
+		return b.Rotate(p, n)
+	})
+}
+
+// Mirror flips the area horizontally around {0, 0}.
+func (a Area) Mirror(b *Board) Area {
+	return a.Map(b.Mirror)
+}
+
+// Map creates a new area having poses mapped by fn.
+func (a Area) Map(fn func(p Pos) Pos) Area {
+	n := Area{pos: make(map[Pos]bool, len(a.pos))}
+	for p := range a.pos {
+		n.pos[fn(p)] = true
+	}
+	return n
+}
+
+func (a Area) Poses() []Pos {
+	poses := make([]Pos, 0, len(a.pos))
+	for p := range a.pos {
+		poses = append(poses, p)
+	}
+	sort.Slice(poses, func(i, j int) bool {
+		if poses[i][0] < poses[j][0] {
+			return true
+		}
+		if poses[i][0] > poses[j][0] {
+			return false
+		}
+		if poses[i][1] < poses[j][1] {
+			return true
+		}
+		return false
+	})
+	return poses
+}
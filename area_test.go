@@ -0,0 +1,46 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func samePoses(a, b []Pos) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAreaOperations(t *testing.T) {
+	a := CreateArea([]Pos{{0, 0}, {1, 0}, {2, 0}})
+	b := CreateArea([]Pos{{1, 0}, {1, 1}})
+	cases := []struct {
+		name string
+		got  Area
+		want []Pos
+	}{
+		{"add", a.Add([]Pos{{0, 0}, {0, 1}}), []Pos{{0, 0}, {0, 1}, {1, 0}, {2, 0}}},
+		{"sub", a.Sub([]Pos{{0, 0}, {0, 1}}), []Pos{{1, 0}, {2, 0}}},
+		{"union", a.Union(b), []Pos{{0, 0}, {1, 0}, {1, 1}, {2, 0}}},
+		{"intersect", a.Intersect(b), []Pos{{1, 0}}},
+		{"difference", a.Difference(b), []Pos{{0, 0}, {2, 0}}},
+		{"translate", b.Translate(Pos{2, -1}), []Pos{{3, -1}, {3, 0}}},
+	}
+	for _, c := range cases {
+		if !samePoses(c.got.Poses(), c.want) {
+			t.Fatalf("%s: want %v, got %v", c.name, c.want, c.got.Poses())
+		}
+	}
+	// operations should not change the original area.
+	if !samePoses(a.Poses(), []Pos{{0, 0}, {1, 0}, {2, 0}}) {
+		t.Fatalf("area is changed: %v", a.Poses())
+	}
+	if !a.Contains(Pos{2, 0}) || a.Contains(Pos{1, 1}) {
+		t.Fatalf("contains: wrong result")
+	}
+}
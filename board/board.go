@@ -0,0 +1,129 @@
+// Package board builds tiled boards on a topology.
+//
+// A topology describes the shape of a board: directions, neighbors, distance and
+// where tiles are drawn on a screen. Packages of board shapes, like quad and hex,
+// implement a Topology and register it, so code using boards doesn't need to know
+// which shape it is.
+package board
+
+import (
+	"sort"
+
+	"github.com/kybin/tiled"
+)
+
+// Topology is the shape of a board.
+type Topology interface {
+	// Kind is the kind of boards built on the topology. See tiled.Board.Kind.
+	Kind() string
+	// Dirs returns names of directions clockwise, starting from north.
+	// A step of tiled.Board.Rotate turns a direction to the next one.
+	Dirs() []string
+	// Offset returns the offset from a position to its neighbor in the direction.
+	Offset(dir string) tiled.Pos
+	// Opposite returns the direction opposite to dir.
+	Opposite(dir string) string
+	// Cost returns cost of a way in the direction.
+	Cost(dir string) int
+	// Corners returns directions of neighbors which a way in the direction slips between,
+	// or nil if the way doesn't. See tiled.Way.Corners.
+	Corners(dir string) []string
+	// Distance returns least number of steps between the two positions.
+	Distance(a, b tiled.Pos) int
+	// Rotate rotates p by n steps clockwise around {0, 0}.
+	Rotate(p tiled.Pos, n int) tiled.Pos
+	// Mirror mirrors p horizontally around {0, 0}.
+	Mirror(p tiled.Pos) tiled.Pos
+	// Line returns positions on the straight line from a to b, including both.
+	Line(a, b tiled.Pos) []tiled.Pos
+	// Poses returns positions of tiles in a board having the width and height.
+	Poses(width, height int) []tiled.Pos
+	// ToPixel returns the center of the tile at p on a screen, when a tile is w pixels wide and h pixels high.
+	// Top left corner of the tile at {0, 0} is at {0, 0}.
+	ToPixel(p tiled.Pos, w, h float64) (x, y float64)
+	// FromPixel returns position of the tile at the point on a screen. It is the inverse of ToPixel.
+	// The tile could be outside of a board.
+	FromPixel(x, y, w, h float64) tiled.Pos
+}
+
+var topologies = make(map[string]Topology)
+
+// Register registers the topology, so boards of its kind could be created and loaded by tiled.
+func Register(t Topology) {
+	topologies[t.Kind()] = t
+	tiled.RegisterBoard(t.Kind(), func(width, height int) *tiled.Board {
+		return New(t, width, height)
+	})
+}
+
+// TopologyOf returns the registered topology the board is built on, or nil if there isn't.
+func TopologyOf(b *tiled.Board) Topology {
+	return topologies[b.Kind]
+}
+
+// New creates a new board on the topology. Tiles are connected to their neighbors by ways.
+func New(t Topology, width, height int) *tiled.Board {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	b := &tiled.Board{
+		Kind:     t.Kind(),
+		Width:    width,
+		Height:   height,
+		TileAt:   make(map[tiled.Pos]*tiled.Tile),
+		Ways:     t.Dirs(),
+		Distance: t.Distance,
+		Rotate:   t.Rotate,
+		Mirror:   t.Mirror,
+		Line:     t.Line,
+		Offset:   t.Offset,
+	}
+	poses := t.Poses(width, height)
+	for _, pos := range poses {
+		b.TileAt[pos] = &tiled.Tile{Pos: pos, Board: b}
+	}
+	for _, pos := range poses {
+		tile := b.TileAt[pos]
+		tile.Ways = make([]*tiled.Way, 0)
+		for _, name := range b.Ways {
+			to := b.TileAt[pos.Add(t.Offset(name))]
+			if to == nil {
+				continue
+			}
+			w := &tiled.Way{Name: name, From: tile, To: to, Cost: t.Cost(name)}
+			for _, c := range t.Corners(name) {
+				w.Corners = append(w.Corners, pos.Add(t.Offset(c)))
+			}
+			tile.Ways = append(tile.Ways, w)
+		}
+	}
+	return b
+}
+
+// DrawOrder sorts the positions in the order their tiles should be drawn on the topology,
+// from back to front, so nearer tiles overlap farther ones. It returns the sorted positions.
+func DrawOrder(t Topology, poses []tiled.Pos) []tiled.Pos {
+	sort.SliceStable(poses, func(i, j int) bool {
+		xi, yi := t.ToPixel(poses[i], 2, 1)
+		xj, yj := t.ToPixel(poses[j], 2, 1)
+		if yi != yj {
+			return yi < yj
+		}
+		return xi < xj
+	})
+	return poses
+}
+
+// Opposite returns the direction half turn from dir in dirs, for topologies
+// having directions in opposite pairs. It returns "" if dir is not in dirs.
+func Opposite(dirs []string, dir string) string {
+	for i, d := range dirs {
+		if d == dir {
+			return dirs[(i+len(dirs)/2)%len(dirs)]
+		}
+	}
+	return ""
+}
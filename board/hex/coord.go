@@ -0,0 +1,121 @@
+package hex
+
+import (
+	"math"
+
+	"github.com/kybin/tiled"
+)
+
+// Axial is axial coordinates of a hex.
+// Q increases toward east, and R increases toward south.
+type Axial struct {
+	Q, R int
+}
+
+// Cube is cube coordinates of a hex. Q + R + S is always 0.
+type Cube struct {
+	Q, R, S int
+}
+
+// AxialOf returns axial coordinates of the position.
+func AxialOf(p tiled.Pos) Axial {
+	return Axial{Q: p[0], R: (p[1] - p[0]) / 2}
+}
+
+// CubeOf returns cube coordinates of the position.
+func CubeOf(p tiled.Pos) Cube {
+	return AxialOf(p).Cube()
+}
+
+// Pos returns the position of the hex on a board.
+func (a Axial) Pos() tiled.Pos {
+	return tiled.Pos{a.Q, 2*a.R + a.Q}
+}
+
+func (a Axial) Cube() Cube {
+	return Cube{Q: a.Q, R: a.R, S: -a.Q - a.R}
+}
+
+// Pos returns the position of the hex on a board.
+func (c Cube) Pos() tiled.Pos {
+	return c.Axial().Pos()
+}
+
+func (c Cube) Axial() Axial {
+	return Axial{Q: c.Q, R: c.R}
+}
+
+// Round returns the hex nearest to the fractional cube coordinates.
+func Round(q, r, s float64) Cube {
+	rq, rr, rs := math.Round(q), math.Round(r), math.Round(s)
+	dq, dr, ds := math.Abs(rq-q), math.Abs(rr-r), math.Abs(rs-s)
+	if dq > dr && dq > ds {
+		rq = -rr - rs
+	} else if dr > ds {
+		rr = -rq - rs
+	} else {
+		rs = -rq - rr
+	}
+	return Cube{Q: int(rq), R: int(rr), S: int(rs)}
+}
+
+// Distance returns least number of steps between the two positions.
+func Distance(a, b tiled.Pos) int {
+	ca, cb := CubeOf(a), CubeOf(b)
+	return (abs(ca.Q-cb.Q) + abs(ca.R-cb.R) + abs(ca.S-cb.S)) / 2
+}
+
+// Ring returns positions n steps away from the center, clockwise from north.
+// It returns only the center when n is 0.
+func Ring(center tiled.Pos, n int) []tiled.Pos {
+	if n <= 0 {
+		return []tiled.Pos{center}
+	}
+	poses := make([]tiled.Pos, 0, 6*n)
+	p := center
+	for i := 0; i < n; i++ {
+		p = p.Add(dirs["N"])
+	}
+	for _, dir := range []string{"SE", "S", "SW", "NW", "N", "NE"} {
+		for i := 0; i < n; i++ {
+			poses = append(poses, p)
+			p = p.Add(dirs[dir])
+		}
+	}
+	return poses
+}
+
+// Spiral returns positions within n steps from the center,
+// starting from the center and followed by rings from inner to outer.
+func Spiral(center tiled.Pos, n int) []tiled.Pos {
+	poses := []tiled.Pos{center}
+	for i := 1; i <= n; i++ {
+		poses = append(poses, Ring(center, i)...)
+	}
+	return poses
+}
+
+// Line returns positions of hexes on the straight line from a to b.
+func Line(a, b tiled.Pos) []tiled.Pos {
+	n := Distance(a, b)
+	poses := make([]tiled.Pos, 0, n+1)
+	// interpolate in cube coordinates, nudged a little not to be on edges.
+	ca, cb := CubeOf(a), CubeOf(b)
+	aq, ar, as := float64(ca.Q)+1e-6, float64(ca.R)+2e-6, float64(ca.S)-3e-6
+	bq, br, bs := float64(cb.Q)+1e-6, float64(cb.R)+2e-6, float64(cb.S)-3e-6
+	for i := 0; i <= n; i++ {
+		t := 0.0
+		if n != 0 {
+			t = float64(i) / float64(n)
+		}
+		poses = append(poses, Round(aq+(bq-aq)*t, ar+(br-ar)*t, as+(bs-as)*t).Pos())
+	}
+	return poses
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,65 @@
+package hex
+
+import (
+	"testing"
+
+	"github.com/kybin/tiled"
+)
+
+func TestCoord(t *testing.T) {
+	b := NewBoard(4, 4)
+	for pos := range b.TileAt {
+		c := CubeOf(pos)
+		if c.Q+c.R+c.S != 0 {
+			t.Fatalf("cube of %v: want sum 0, got %v", pos, c)
+		}
+		if c.Pos() != pos || AxialOf(pos).Pos() != pos {
+			t.Fatalf("coordinates of %v: want same position, got %v", pos, c.Pos())
+		}
+	}
+	if a := AxialOf(tiled.Pos{1, 3}); a != (Axial{Q: 1, R: 1}) {
+		t.Fatalf("axial: want %v, got %v", Axial{Q: 1, R: 1}, a)
+	}
+	if c := Round(0.7, -0.2, -0.5); c != (Cube{Q: 1, R: 0, S: -1}) {
+		t.Fatalf("round: want %v, got %v", Cube{Q: 1, R: 0, S: -1}, c)
+	}
+	if c := Round(0.4, 0.4, -0.8); c != (Cube{Q: 0, R: 1, S: -1}) {
+		t.Fatalf("round: want %v, got %v", Cube{Q: 0, R: 1, S: -1}, c)
+	}
+}
+
+func TestRing(t *testing.T) {
+	center := tiled.Pos{3, 7}
+	for n := 0; n <= 3; n++ {
+		ring := Ring(center, n)
+		want := 6 * n
+		if n == 0 {
+			want = 1
+		}
+		if len(ring) != want {
+			t.Fatalf("ring %v: want %v poses, got %v", n, want, len(ring))
+		}
+		for i, p := range ring {
+			if d := Distance(center, p); d != n {
+				t.Fatalf("ring %v: want distance %v, got %v at %v", n, n, d, p)
+			}
+			if n > 0 && Distance(p, ring[(i+1)%len(ring)]) != 1 {
+				t.Fatalf("ring %v: disconnected %v", n, ring)
+			}
+		}
+	}
+	if r := Ring(tiled.Pos{}, 1); r[0] != (tiled.Pos{0, -2}) || r[1] != (tiled.Pos{1, -1}) {
+		t.Fatalf("ring: want clockwise from north, got %v", r)
+	}
+	spiral := Spiral(center, 2)
+	seen := make(map[tiled.Pos]bool)
+	for _, p := range spiral {
+		if seen[p] || Distance(center, p) > 2 {
+			t.Fatalf("spiral: got %v", spiral)
+		}
+		seen[p] = true
+	}
+	if len(spiral) != 19 || Around2Area.Len() != 18 {
+		t.Fatalf("spiral: want 19 poses, got %v", len(spiral))
+	}
+}
@@ -2,55 +2,110 @@ package hex
 
 import (
 	"github.com/kybin/tiled"
+	"github.com/kybin/tiled/board"
 )
 
-// TODO: write tests
+func init() {
+	board.Register(Topology{})
+}
+
+// Topology is topology of flat topped hex boards.
+//
+// Tiles in odd columns are placed half tile lower than even columns.
+// Position of a tile is {x, 2*y + x%2} for x in [0, width) and y in [0, height).
+// They are doubled coordinates of hexes, see AxialOf and CubeOf for other coordinates.
+type Topology struct{}
+
+var dirs = map[string]tiled.Pos{
+	"N":  {0, -2},
+	"NE": {1, -1},
+	"SE": {1, 1},
+	"S":  {0, 2},
+	"SW": {-1, 1},
+	"NW": {-1, -1},
+}
 
 // NewBoard creates a new hex board.
 func NewBoard(width, height int) *tiled.Board {
-	if width < 1 {
-		width = 1
-	}
-	if height < 1 {
-		height = 1
-	}
-	// note that dirs are not having same distance.
-	// it makes somewhat squeezed board, but we have integer positions instead.
-	dirs := make(map[string]tiled.Pos)
-	dirs["N"] = tiled.Pos{0, -2}
-	dirs["NW"] = tiled.Pos{1, -1}
-	dirs["SW"] = tiled.Pos{1, 1}
-	dirs["S"] = tiled.Pos{0, 2}
-	dirs["SE"] = tiled.Pos{-1, 1}
-	dirs["NE"] = tiled.Pos{-1, -1}
-	tileAt := make(map[tiled.Pos]*tiled.Tile)
-	for y := 0; y <= height+1; y++ {
-		for x := 0; x <= width+2; x += 2 {
-			if x-2 <= width && y-1 <= height {
-				tileAt[tiled.Pos{x, y}] = &tiled.Tile{}
-			}
+	return board.New(Topology{}, width, height)
+}
+
+func (Topology) Kind() string {
+	return "hex"
+}
+
+func (Topology) Dirs() []string {
+	return []string{"N", "NE", "SE", "S", "SW", "NW"}
+}
+
+func (Topology) Offset(dir string) tiled.Pos {
+	return dirs[dir]
+}
+
+func (t Topology) Opposite(dir string) string {
+	return board.Opposite(t.Dirs(), dir)
+}
+
+func (Topology) Cost(dir string) int {
+	return 1
+}
+
+func (Topology) Corners(dir string) []string {
+	return nil
+}
+
+func (Topology) Distance(a, b tiled.Pos) int {
+	return Distance(a, b)
+}
+
+func (Topology) Rotate(p tiled.Pos, n int) tiled.Pos {
+	return rotate(p, n)
+}
+
+func (Topology) Mirror(p tiled.Pos) tiled.Pos {
+	return mirror(p)
+}
+
+func (Topology) Line(a, b tiled.Pos) []tiled.Pos {
+	return Line(a, b)
+}
+
+func (Topology) Poses(width, height int) []tiled.Pos {
+	poses := make([]tiled.Pos, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			poses = append(poses, tiled.Pos{x, 2*y + x%2})
 		}
 	}
-	board := &tiled.Board{
-		Width:  width,
-		Height: Height,
-		TileAt: tileAt,
-		Ways:   []string{"N", "NW", "SW", "S", "SE", "NE"},
-	}
-	// find ways to nearby tiles
-	for y := 0; y <= height+1; y++ {
-		for x := 0; x <= width+2; x += 2 {
-			pos := tiled.Pos{x, y}
-			t := g.TileAt[pos]
-			t.ways = make([]*tiled.Way, 0)
-			for name, dir := range dirs {
-				at := pos.Add(dir)
-				if g.TileAt[at] != nil {
-					t.Ways = append(t.Ways, &Way{Name: name, From: t, To: g.TileAt[at], Cost: 1})
-				}
-			}
-		}
+	return poses
+}
+
+// ToPixel returns the center of the tile at p.
+// Columns are 3/4 of w apart, as a hex tile of the column fits into the gap of the other columns.
+func (Topology) ToPixel(p tiled.Pos, w, h float64) (x, y float64) {
+	return w/2 + float64(p[0])*w*3/4, h/2 + float64(p[1])*h/2
+}
+
+func (Topology) FromPixel(x, y, w, h float64) tiled.Pos {
+	q := (x - w/2) / (w * 3 / 4)
+	r := ((y-h/2)/(h/2) - q) / 2
+	return Round(q, r, -q-r).Pos()
+}
+
+// rotate rotates p by n * 60 degrees clockwise around {0, 0}.
+func rotate(p tiled.Pos, n int) tiled.Pos {
+	n = ((n % 6) + 6) % 6
+	c := CubeOf(p)
+	for i := 0; i < n; i++ {
+		c = Cube{Q: -c.R, R: -c.S, S: -c.Q}
 	}
+	return c.Pos()
+}
+
+func mirror(p tiled.Pos) tiled.Pos {
+	return tiled.Pos{-p[0], p[1]}
 }
 
 var AroundArea = tiled.CreateArea([]tiled.Pos{{0, -2}, {1, -1}, {1, 1}, {0, 2}, {-1, 1}, {-1, -1}})
+
+var Around2Area = tiled.CreateArea(Spiral(tiled.Pos{}, 2)[1:])
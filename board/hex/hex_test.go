@@ -0,0 +1,165 @@
+package hex
+
+import (
+	"testing"
+
+	"github.com/kybin/tiled"
+	"github.com/kybin/tiled/board"
+)
+
+func TestNewBoard(t *testing.T) {
+	b := NewBoard(3, 3)
+	if len(b.TileAt) != 9 {
+		t.Fatalf("number of tiles: want 9, got %v", len(b.TileAt))
+	}
+	// number of neighbors in the 3x3 board, by tile position.
+	want := map[tiled.Pos]int{
+		{0, 0}: 2, {1, 1}: 5, {2, 0}: 2,
+		{0, 2}: 4, {1, 3}: 6, {2, 2}: 4,
+		{0, 4}: 3, {1, 5}: 3, {2, 4}: 3,
+	}
+	for pos, n := range want {
+		tile := b.TileAt[pos]
+		if tile == nil {
+			t.Fatalf("tile at %v: want tile, got nil", pos)
+		}
+		if len(tile.Ways) != n {
+			t.Fatalf("ways of %v: want %v, got %v", pos, n, len(tile.Ways))
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	b := NewBoard(5, 5)
+	from := tiled.Pos{0, 0}
+	// compare with breadth first search.
+	dist := map[*tiled.Tile]int{b.TileAt[from]: 0}
+	queue := []*tiled.Tile{b.TileAt[from]}
+	for len(queue) != 0 {
+		tile := queue[0]
+		queue = queue[1:]
+		for _, w := range tile.Ways {
+			if _, ok := dist[w.To]; ok {
+				continue
+			}
+			dist[w.To] = dist[tile] + 1
+			queue = append(queue, w.To)
+		}
+	}
+	for tile, d := range dist {
+		got := b.Distance(from, tile.Pos)
+		if got != d {
+			t.Fatalf("distance to %v: want %v, got %v", tile.Pos, d, got)
+		}
+	}
+}
+
+func TestMoveTo(t *testing.T) {
+	b := NewBoard(4, 4)
+	c := &tiled.Character{Origin: b.TileAt[tiled.Pos{0, 0}], RemainingPoints: 5}
+	c.Origin.Occupier = c
+	b.TileAt[tiled.Pos{1, 1}].Occupier = &tiled.Character{}
+	to := b.TileAt[tiled.Pos{3, 3}]
+	if !c.MoveTo(to) {
+		t.Fatalf("move to %v: want true, got false", to.Pos)
+	}
+	if c.SpentPoints != 4 {
+		t.Fatalf("spent points: want 4, got %v", c.SpentPoints)
+	}
+	if to.Occupier != c {
+		t.Fatalf("occupier of %v: want the character", to.Pos)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	b := NewBoard(1, 1)
+	// directions in clockwise order.
+	dirs := []tiled.Pos{{0, -2}, {1, -1}, {1, 1}, {0, 2}, {-1, 1}, {-1, -1}}
+	for i, d := range dirs {
+		for n := -6; n <= 6; n++ {
+			want := dirs[((i+n)%6+6)%6]
+			got := b.Rotate(d, n)
+			if got != want {
+				t.Fatalf("rotate %v by %v: want %v, got %v", d, n, want, got)
+			}
+		}
+	}
+	far := tiled.Pos{1, -3}
+	if got := b.Rotate(far, 1); got != (tiled.Pos{2, 0}) {
+		t.Fatalf("rotate %v: want %v, got %v", far, tiled.Pos{2, 0}, got)
+	}
+	if got := b.RotationToward(tiled.Pos{0, 0}, tiled.Pos{-2, 2}); got != 4 {
+		t.Fatalf("rotation toward: want 4, got %v", got)
+	}
+}
+
+func TestLine(t *testing.T) {
+	b := NewBoard(5, 5)
+	for _, from := range b.TileAt {
+		for _, to := range b.TileAt {
+			l := b.Line(from.Pos, to.Pos)
+			if len(l) != b.Distance(from.Pos, to.Pos)+1 {
+				t.Fatalf("line %v-%v: want %v poses, got %v", from.Pos, to.Pos, b.Distance(from.Pos, to.Pos)+1, l)
+			}
+			if l[0] != from.Pos || l[len(l)-1] != to.Pos {
+				t.Fatalf("line %v-%v: got %v", from.Pos, to.Pos, l)
+			}
+			for i := 1; i < len(l); i++ {
+				if b.Distance(l[i-1], l[i]) != 1 {
+					t.Fatalf("line %v-%v: disconnected %v", from.Pos, to.Pos, l)
+				}
+			}
+		}
+	}
+}
+
+func TestPush(t *testing.T) {
+	b := NewBoard(5, 5)
+	src := &tiled.Character{HP: 10}
+	c := &tiled.Character{HP: 10}
+	src.Place(b.TileAt[tiled.Pos{0, 2}])
+	c.Place(b.TileAt[tiled.Pos{1, 3}])
+	s := &tiled.Stage{Board: b, CollisionDamage: 1}
+	dir := b.DirectionToward(src.Tile().Pos, c.Tile().Pos)
+	s.Push(src, c, dir, 5)
+	if c.Tile().Pos != (tiled.Pos{4, 6}) || c.HP != 9 {
+		t.Fatalf("push: want hp 9 at %v, got %v at %v", tiled.Pos{4, 6}, c.HP, c.Tile().Pos)
+	}
+	s.Pull(src, c, 5)
+	if c.Tile().Pos != (tiled.Pos{1, 3}) {
+		t.Fatalf("pull: want %v, got %v", tiled.Pos{1, 3}, c.Tile().Pos)
+	}
+}
+
+func TestTopology(t *testing.T) {
+	b := NewBoard(3, 3)
+	if board.TopologyOf(b) == nil {
+		t.Fatalf("topology of the board: want hex topology, got nil")
+	}
+	from := b.TileAt[tiled.Pos{1, 3}]
+	for i, w := range from.Ways {
+		if w.Name != b.Ways[i] {
+			t.Fatalf("way %v: want %v, got %v", i, b.Ways[i], w.Name)
+		}
+		if d := b.DirectionToward(from.Pos, w.To.Pos); d != w.Name {
+			t.Fatalf("direction toward %v: want %v, got %v", w.To.Pos, w.Name, d)
+		}
+		back := w.To.Way(Topology{}.Opposite(w.Name))
+		if back == nil || back.To != from {
+			t.Fatalf("opposite way of %v: want way back to %v", w.Name, from.Pos)
+		}
+	}
+	if w := from.Way("NE"); w.To.Pos != (tiled.Pos{2, 2}) {
+		t.Fatalf("way NE: want %v, got %v", tiled.Pos{2, 2}, w.To.Pos)
+	}
+	w, h := 32.0, 28.0
+	for pos := range b.TileAt {
+		x, y := Topology{}.ToPixel(pos, w, h)
+		// points near to the center are in the tile.
+		for _, d := range [][2]float64{{0, 0}, {w / 3, 0}, {-w / 3, 0}, {0, h / 3}, {w / 5, -h / 3}} {
+			if got := (Topology{}).FromPixel(x+d[0], y+d[1], w, h); got != pos {
+				t.Fatalf("pixel of %v: want %v, got %v", pos, pos, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package iso provides isometric boards.
+//
+// Both diamond and staggered boards have square tiles drawn as diamonds on a screen,
+// connected to 4 neighbors through their edges. They are different by the shape of boards.
+// Use board.DrawOrder to draw tiles from back to front.
+package iso
+
+import (
+	"math"
+
+	"github.com/kybin/tiled"
+	"github.com/kybin/tiled/board"
+	"github.com/kybin/tiled/board/quad"
+)
+
+func init() {
+	board.Register(Diamond{})
+	board.Register(Staggered{})
+}
+
+var dirs = []string{"NE", "SE", "SW", "NW"}
+
+// grid is quad topology of tiles, when a tile is rotated to be a square.
+// Ways of iso boards are named by the directions on a screen, not by ones of quad boards.
+var grid = quad.Topology{}
+
+// gridDirs are names of the grid directions, in the same order as dirs.
+var gridDirs = grid.Dirs()
+
+func gridDir(dir string) string {
+	for i, d := range dirs {
+		if d == dir {
+			return gridDirs[i]
+		}
+	}
+	return ""
+}
+
+// Diamond is topology of diamond shaped isometric boards.
+// Positions of tiles are {x, y} for x in [0, width) and y in [0, height),
+// x increases toward south east, and y increases toward south west on a screen.
+type Diamond struct{}
+
+// NewDiamond creates a new diamond shaped isometric board.
+func NewDiamond(width, height int) *tiled.Board {
+	return board.New(Diamond{}, width, height)
+}
+
+func (Diamond) Kind() string {
+	return "iso"
+}
+
+// Dirs returns directions clockwise, starting from north east as iso boards don't have north.
+func (Diamond) Dirs() []string {
+	return dirs
+}
+
+func (Diamond) Offset(dir string) tiled.Pos {
+	return grid.Offset(gridDir(dir))
+}
+
+func (t Diamond) Opposite(dir string) string {
+	return board.Opposite(t.Dirs(), dir)
+}
+
+func (Diamond) Cost(dir string) int {
+	return 1
+}
+
+func (Diamond) Corners(dir string) []string {
+	return nil
+}
+
+func (Diamond) Distance(a, b tiled.Pos) int {
+	return grid.Distance(a, b)
+}
+
+func (Diamond) Rotate(p tiled.Pos, n int) tiled.Pos {
+	return grid.Rotate(p, n)
+}
+
+func (Diamond) Mirror(p tiled.Pos) tiled.Pos {
+	return grid.Mirror(p)
+}
+
+func (Diamond) Line(a, b tiled.Pos) []tiled.Pos {
+	return grid.Line(a, b)
+}
+
+func (Diamond) Poses(width, height int) []tiled.Pos {
+	return grid.Poses(width, height)
+}
+
+// ToPixel returns the center of the tile at p.
+// Tiles having y greater than x are at the left of the tile at {0, 0}, which makes negative x.
+func (Diamond) ToPixel(p tiled.Pos, w, h float64) (x, y float64) {
+	return w/2 + float64(p[0]-p[1])*w/2, h/2 + float64(p[0]+p[1])*h/2
+}
+
+func (Diamond) FromPixel(x, y, w, h float64) tiled.Pos {
+	a := (x - w/2) / (w / 2)
+	b := (y - h/2) / (h / 2)
+	return tiled.Pos{round((a + b) / 2), round((b - a) / 2)}
+}
+
+// Staggered is topology of rectangle shaped isometric boards.
+// Rows are half tile apart, and tiles in odd rows are placed half tile right of even rows.
+// Position of a tile is {2*x + y%2, y} for x in [0, width) and y in [0, height),
+// so neighbors are always at the same offsets as hex boards do.
+type Staggered struct{}
+
+// NewStaggered creates a new staggered isometric board.
+func NewStaggered(width, height int) *tiled.Board {
+	return board.New(Staggered{}, width, height)
+}
+
+func (Staggered) Kind() string {
+	return "iso-staggered"
+}
+
+// Dirs returns directions clockwise, starting from north east as iso boards don't have north.
+func (Staggered) Dirs() []string {
+	return dirs
+}
+
+func (Staggered) Offset(dir string) tiled.Pos {
+	return fromGrid(grid.Offset(gridDir(dir)))
+}
+
+func (t Staggered) Opposite(dir string) string {
+	return board.Opposite(t.Dirs(), dir)
+}
+
+func (Staggered) Cost(dir string) int {
+	return 1
+}
+
+func (Staggered) Corners(dir string) []string {
+	return nil
+}
+
+func (Staggered) Distance(a, b tiled.Pos) int {
+	return grid.Distance(toGrid(a), toGrid(b))
+}
+
+func (Staggered) Rotate(p tiled.Pos, n int) tiled.Pos {
+	return fromGrid(grid.Rotate(toGrid(p), n))
+}
+
+func (Staggered) Mirror(p tiled.Pos) tiled.Pos {
+	return fromGrid(grid.Mirror(toGrid(p)))
+}
+
+func (Staggered) Line(a, b tiled.Pos) []tiled.Pos {
+	poses := grid.Line(toGrid(a), toGrid(b))
+	for i, p := range poses {
+		poses[i] = fromGrid(p)
+	}
+	return poses
+}
+
+func (Staggered) Poses(width, height int) []tiled.Pos {
+	poses := make([]tiled.Pos, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			poses = append(poses, tiled.Pos{2*x + y%2, y})
+		}
+	}
+	return poses
+}
+
+func (Staggered) ToPixel(p tiled.Pos, w, h float64) (x, y float64) {
+	return w/2 + float64(p[0])*w/2, h/2 + float64(p[1])*h/2
+}
+
+func (Staggered) FromPixel(x, y, w, h float64) tiled.Pos {
+	a := (x - w/2) / (w / 2)
+	b := (y - h/2) / (h / 2)
+	return fromGrid(tiled.Pos{round((a + b) / 2), round((b - a) / 2)})
+}
+
+// toGrid converts a staggered position to the grid position, which is the same as of diamond boards.
+func toGrid(p tiled.Pos) tiled.Pos {
+	return tiled.Pos{(p[0] + p[1]) / 2, (p[1] - p[0]) / 2}
+}
+
+// fromGrid converts a grid position to the staggered position.
+func fromGrid(p tiled.Pos) tiled.Pos {
+	return tiled.Pos{p[0] - p[1], p[0] + p[1]}
+}
+
+func round(f float64) int {
+	return int(math.Floor(f + 0.5))
+}
@@ -0,0 +1,123 @@
+package iso
+
+import (
+	"testing"
+
+	"github.com/kybin/tiled"
+	"github.com/kybin/tiled/board"
+)
+
+func TestNeighbors(t *testing.T) {
+	// signs of screen offsets to neighbors.
+	want := map[string][2]float64{"NE": {1, -1}, "SE": {1, 1}, "SW": {-1, 1}, "NW": {-1, -1}}
+	for _, topo := range []board.Topology{Diamond{}, Staggered{}} {
+		b := board.New(topo, 4, 4)
+		if len(b.TileAt) != 16 {
+			t.Fatalf("%v: number of tiles: want 16, got %v", topo.Kind(), len(b.TileAt))
+		}
+		for _, tile := range b.TileAt {
+			x, y := topo.ToPixel(tile.Pos, 64, 32)
+			for _, w := range tile.Ways {
+				nx, ny := topo.ToPixel(w.To.Pos, 64, 32)
+				if nx-x != want[w.Name][0]*32 || ny-y != want[w.Name][1]*16 {
+					t.Fatalf("%v: way %v of %v: want offset %v, got %v", topo.Kind(), w.Name, tile.Pos, want[w.Name], [2]float64{nx - x, ny - y})
+				}
+				if back := w.To.Way(topo.Opposite(w.Name)); back == nil || back.To != tile {
+					t.Fatalf("%v: opposite way of %v: want way back to %v", topo.Kind(), w.Name, tile.Pos)
+				}
+			}
+		}
+		if d := b.Rotate(topo.Offset("NE"), 1); d != topo.Offset("SE") {
+			t.Fatalf("%v: rotate NE: want %v, got %v", topo.Kind(), topo.Offset("SE"), d)
+		}
+	}
+	if n := len(NewStaggered(3, 3).TileAt[tiled.Pos{3, 1}].Ways); n != 4 {
+		t.Fatalf("ways of a staggered tile: want 4, got %v", n)
+	}
+}
+
+func TestPixel(t *testing.T) {
+	w, h := 64.0, 32.0
+	for _, topo := range []board.Topology{Diamond{}, Staggered{}} {
+		b := board.New(topo, 4, 4)
+		for pos := range b.TileAt {
+			x, y := topo.ToPixel(pos, w, h)
+			// points inside of the diamond.
+			for _, d := range [][2]float64{{0, 0}, {w / 3, 0}, {-w / 3, 0}, {0, h / 3}, {w / 5, -h / 5}} {
+				if got := topo.FromPixel(x+d[0], y+d[1], w, h); got != pos {
+					t.Fatalf("%v: pixel of %v: want %v, got %v", topo.Kind(), pos, pos, got)
+				}
+			}
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	for _, topo := range []board.Topology{Diamond{}, Staggered{}} {
+		b := board.New(topo, 5, 5)
+		for _, from := range b.TileAt {
+			// compare with breadth first search.
+			dist := map[*tiled.Tile]int{from: 0}
+			queue := []*tiled.Tile{from}
+			for len(queue) != 0 {
+				tile := queue[0]
+				queue = queue[1:]
+				for _, w := range tile.Ways {
+					if _, ok := dist[w.To]; ok {
+						continue
+					}
+					dist[w.To] = dist[tile] + 1
+					queue = append(queue, w.To)
+				}
+			}
+			for tile, d := range dist {
+				if got := b.Distance(from.Pos, tile.Pos); got != d {
+					t.Fatalf("%v: distance %v-%v: want %v, got %v", topo.Kind(), from.Pos, tile.Pos, d, got)
+				}
+			}
+			for _, to := range b.TileAt {
+				l := b.Line(from.Pos, to.Pos)
+				if l[0] != from.Pos || l[len(l)-1] != to.Pos {
+					t.Fatalf("%v: line %v-%v: got %v", topo.Kind(), from.Pos, to.Pos, l)
+				}
+				for _, p := range l {
+					if b.TileAt[p] == nil {
+						t.Fatalf("%v: line %v-%v: got %v outside of the board", topo.Kind(), from.Pos, to.Pos, l)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDrawOrder(t *testing.T) {
+	poses := board.DrawOrder(Diamond{}, []tiled.Pos{{1, 1}, {0, 1}, {0, 0}, {1, 0}})
+	want := []tiled.Pos{{0, 0}, {0, 1}, {1, 0}, {1, 1}}
+	for i := range want {
+		if poses[i] != want[i] {
+			t.Fatalf("draw order: want %v, got %v", want, poses)
+		}
+	}
+}
+
+func TestFacing(t *testing.T) {
+	for _, topo := range []board.Topology{Diamond{}, Staggered{}} {
+		b := board.New(topo, 5, 5)
+		for _, tile := range b.TileAt {
+			for _, w := range tile.Ways {
+				if d := b.DirectionToward(tile.Pos, w.To.Pos); d != w.Name {
+					t.Fatalf("%v: direction toward %v from %v: want %v, got %v", topo.Kind(), w.To.Pos, tile.Pos, w.Name, d)
+				}
+			}
+		}
+		center := b.TileAt[topo.Poses(5, 5)[12]]
+		c := &tiled.Character{Facing: "NE"}
+		c.Place(center)
+		want := map[string]tiled.Side{"NE": tiled.Front, "SE": tiled.Flank, "SW": tiled.Back, "NW": tiled.Flank}
+		for dir, side := range want {
+			if got := c.SideOf(center.Way(dir).To.Pos); got != side {
+				t.Fatalf("%v: side of %v: want %v, got %v", topo.Kind(), dir, side, got)
+			}
+		}
+	}
+}
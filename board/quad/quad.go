@@ -1,41 +1,216 @@
 package quad
 
 import (
+	"math"
+
 	"github.com/kybin/tiled"
+	"github.com/kybin/tiled/board"
 )
 
-// NewBoard creates a new quad board.
+func init() {
+	board.Register(Topology{})
+	board.Register(Topology{Diagonal: true})
+}
+
+// Topology is topology of quad boards. Positions of tiles are {x, y} for x in [0, width) and y in [0, height).
+type Topology struct {
+	// Diagonal connects tiles to their diagonal neighbors as well.
+	// Kind of the boards will be "quad8" instead of "quad".
+	Diagonal bool
+	// OrthogonalCost and DiagonalCost are costs of orthogonal and diagonal ways.
+	// OrthogonalCost is 1 when it is 0, and DiagonalCost is OrthogonalCost when it is 0.
+	// Scale both for a fractional ratio. eg. 2 and 3 makes diagonal ways cost 1.5 times.
+	OrthogonalCost int
+	DiagonalCost   int
+	// CornerCut decides whether characters could pass a diagonal way
+	// between its orthogonal neighbors occupied by others.
+	CornerCut tiled.CornerCut
+}
+
+var dirs = map[string]tiled.Pos{
+	"N":  {0, -1},
+	"NE": {1, -1},
+	"E":  {1, 0},
+	"SE": {1, 1},
+	"S":  {0, 1},
+	"SW": {-1, 1},
+	"W":  {-1, 0},
+	"NW": {-1, -1},
+}
+
+// corners are orthogonal directions beside diagonal directions.
+var corners = map[string][]string{
+	"NE": {"N", "E"},
+	"SE": {"S", "E"},
+	"SW": {"S", "W"},
+	"NW": {"N", "W"},
+}
+
+// NewBoard creates a new quad board having 4 directions.
 func NewBoard(width, height int) *tiled.Board {
-	if width < 1 {
-		width = 1
-	}
-	if height < 1 {
-		height = 1
-	}
-	dirs := make(map[string]tiled.Pos)
-	dirs["N"] = tiled.Pos{0, -1}
-	dirs["W"] = tiled.Pos{1, 0}
-	dirs["S"] = tiled.Pos{0, 1}
-	dirs["E"] = tiled.Pos{-1, 0}
-	board := &tiled.Board{
-		Width:  width,
-		Height: Height,
-		Tiles:  make([]*tiled.Tile, width*height),
-		Ways:   []string{"N", "W", "S", "E"},
+	return Topology{}.NewBoard(width, height)
+}
+
+// NewBoard creates a new quad board on the topology.
+func (t Topology) NewBoard(width, height int) *tiled.Board {
+	b := board.New(t, width, height)
+	b.CornerCut = t.CornerCut
+	return b
+}
+
+func (t Topology) Kind() string {
+	if t.Diagonal {
+		return "quad8"
+	}
+	return "quad"
+}
+
+func (t Topology) Dirs() []string {
+	if t.Diagonal {
+		return []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+	}
+	return []string{"N", "E", "S", "W"}
+}
+
+func (Topology) Offset(dir string) tiled.Pos {
+	return dirs[dir]
+}
+
+func (t Topology) Opposite(dir string) string {
+	return board.Opposite(t.Dirs(), dir)
+}
+
+func (t Topology) Cost(dir string) int {
+	cost := t.OrthogonalCost
+	if cost <= 0 {
+		cost = 1
+	}
+	if corners[dir] != nil && t.DiagonalCost > 0 {
+		cost = t.DiagonalCost
+	}
+	return cost
+}
+
+func (Topology) Corners(dir string) []string {
+	return corners[dir]
+}
+
+// Distance returns least number of steps between the two positions.
+func (t Topology) Distance(a, b tiled.Pos) int {
+	if t.Diagonal {
+		return max(abs(a[0]-b[0]), abs(a[1]-b[1]))
+	}
+	return distance(a, b)
+}
+
+// Rotate rotates p by n * 90 degrees clockwise around {0, 0}, or by n * 45 degrees for diagonal boards.
+func (t Topology) Rotate(p tiled.Pos, n int) tiled.Pos {
+	if t.Diagonal {
+		return rotate8(p, n)
 	}
+	return rotate(p, n)
+}
+
+func (Topology) Mirror(p tiled.Pos) tiled.Pos {
+	return mirror(p)
+}
+
+func (Topology) Line(a, b tiled.Pos) []tiled.Pos {
+	return line(a, b)
+}
+
+func (Topology) Poses(width, height int) []tiled.Pos {
+	poses := make([]tiled.Pos, 0, width*height)
 	for y := 0; y < height; y++ {
 		for x := 0; x < width; x++ {
-			pos := tiled.Pos{x, y}
-			t := board.TileAt[pos]
-			t.Ways = make([]*tiled.Way, 0)
-			for name, dir := range dirs {
-				at := pos.Add(dir)
-				if board.TileAt[at] != nil {
-					t.Ways = append(t.Ways, &Way{Name: name, From: t, To: board.TileAt[at], Cost: 1})
-				}
-			}
+			poses = append(poses, tiled.Pos{x, y})
+		}
+	}
+	return poses
+}
+
+func (Topology) ToPixel(p tiled.Pos, w, h float64) (x, y float64) {
+	return (float64(p[0]) + 0.5) * w, (float64(p[1]) + 0.5) * h
+}
+
+func (Topology) FromPixel(x, y, w, h float64) tiled.Pos {
+	return tiled.Pos{int(math.Floor(x / w)), int(math.Floor(y / h))}
+}
+
+// distance is manhattan distance of the two positions.
+func distance(a, b tiled.Pos) int {
+	return abs(a[0]-b[0]) + abs(a[1]-b[1])
+}
+
+// rotate rotates p by n * 90 degrees clockwise around {0, 0}.
+func rotate(p tiled.Pos, n int) tiled.Pos {
+	n = ((n % 4) + 4) % 4
+	for i := 0; i < n; i++ {
+		p = tiled.Pos{-p[1], p[0]}
+	}
+	return p
+}
+
+// rotate8 rotates p by n * 45 degrees clockwise around {0, 0}.
+// A position moves along the square ring it is on, so squares are rotated into squares.
+func rotate8(p tiled.Pos, n int) tiled.Pos {
+	n = ((n % 8) + 8) % 8
+	k := max(abs(p[0]), abs(p[1]))
+	for i := 0; i < n*k; i++ {
+		switch {
+		case p[1] == -k && p[0] < k:
+			p[0]++
+		case p[0] == k && p[1] < k:
+			p[1]++
+		case p[1] == k && p[0] > -k:
+			p[0]--
+		default:
+			p[1]--
+		}
+	}
+	return p
+}
+
+func mirror(p tiled.Pos) tiled.Pos {
+	return tiled.Pos{-p[0], p[1]}
+}
+
+// line returns positions from a to b with Bresenham's algorithm.
+func line(a, b tiled.Pos) []tiled.Pos {
+	dx := abs(b[0] - a[0])
+	dy := -abs(b[1] - a[1])
+	sx, sy := 1, 1
+	if a[0] > b[0] {
+		sx = -1
+	}
+	if a[1] > b[1] {
+		sy = -1
+	}
+	poses := make([]tiled.Pos, 0, dx-dy+1)
+	p := a
+	e := dx + dy
+	for {
+		poses = append(poses, p)
+		if p == b {
+			return poses
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			p[0] += sx
 		}
+		if e2 <= dx {
+			e += dx
+			p[1] += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
 	}
+	return n
 }
 
 var AxisArea = tiled.CreateArea([]tiled.Pos{{0, 1}, {1, 0}, {0, -1}, {-1, 0}})
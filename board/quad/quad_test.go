@@ -0,0 +1,162 @@
+package quad
+
+import (
+	"testing"
+
+	"github.com/kybin/tiled"
+	"github.com/kybin/tiled/board"
+)
+
+func TestRotate(t *testing.T) {
+	b := NewBoard(1, 1)
+	// a cone facing north.
+	cone := tiled.CreateArea([]tiled.Pos{{0, -1}, {-1, -2}, {0, -2}, {1, -2}})
+	cases := []struct {
+		n    int
+		want []tiled.Pos
+	}{
+		{0, []tiled.Pos{{-1, -2}, {0, -2}, {0, -1}, {1, -2}}},
+		{1, []tiled.Pos{{1, 0}, {2, -1}, {2, 0}, {2, 1}}},
+		{2, []tiled.Pos{{-1, 2}, {0, 1}, {0, 2}, {1, 2}}},
+		{-1, []tiled.Pos{{-2, -1}, {-2, 0}, {-2, 1}, {-1, 0}}},
+	}
+	for _, c := range cases {
+		got := cone.Rotate(b, c.n).Poses()
+		if len(got) != len(c.want) {
+			t.Fatalf("rotate %v: want %v, got %v", c.n, c.want, got)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("rotate %v: want %v, got %v", c.n, c.want, got)
+			}
+		}
+	}
+	mirrored := tiled.CreateArea([]tiled.Pos{{1, 0}, {2, 1}}).Mirror(b).Poses()
+	if mirrored[0] != (tiled.Pos{-2, 1}) || mirrored[1] != (tiled.Pos{-1, 0}) {
+		t.Fatalf("mirror: got %v", mirrored)
+	}
+}
+
+func TestRotationToward(t *testing.T) {
+	b := NewBoard(1, 1)
+	cases := []struct {
+		to   tiled.Pos
+		want int
+	}{
+		{tiled.Pos{0, -3}, 0},
+		{tiled.Pos{3, 1}, 1},
+		{tiled.Pos{1, 4}, 2},
+		{tiled.Pos{-2, 0}, 3},
+	}
+	for _, c := range cases {
+		got := b.RotationToward(tiled.Pos{0, 0}, c.to)
+		if got != c.want {
+			t.Fatalf("rotation toward %v: want %v, got %v", c.to, c.want, got)
+		}
+	}
+}
+
+func TestLine(t *testing.T) {
+	cases := []struct {
+		a, b tiled.Pos
+		want []tiled.Pos
+	}{
+		{tiled.Pos{0, 0}, tiled.Pos{0, 0}, []tiled.Pos{{0, 0}}},
+		{tiled.Pos{0, 0}, tiled.Pos{3, 0}, []tiled.Pos{{0, 0}, {1, 0}, {2, 0}, {3, 0}}},
+		{tiled.Pos{0, 0}, tiled.Pos{-2, -2}, []tiled.Pos{{0, 0}, {-1, -1}, {-2, -2}}},
+		{tiled.Pos{0, 0}, tiled.Pos{4, 2}, []tiled.Pos{{0, 0}, {1, 1}, {2, 1}, {3, 2}, {4, 2}}},
+	}
+	for _, c := range cases {
+		got := line(c.a, c.b)
+		if len(got) != len(c.want) {
+			t.Fatalf("line %v-%v: want %v, got %v", c.a, c.b, c.want, got)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("line %v-%v: want %v, got %v", c.a, c.b, c.want, got)
+			}
+		}
+	}
+}
+
+func TestTopology(t *testing.T) {
+	b := NewBoard(3, 3)
+	if board.TopologyOf(b) == nil {
+		t.Fatalf("topology of the board: want quad topology, got nil")
+	}
+	want := map[string]tiled.Pos{"N": {1, 0}, "E": {2, 1}, "S": {1, 2}, "W": {0, 1}}
+	for _, w := range b.TileAt[tiled.Pos{1, 1}].Ways {
+		if w.To.Pos != want[w.Name] {
+			t.Fatalf("way %v: want %v, got %v", w.Name, want[w.Name], w.To.Pos)
+		}
+		back := w.To.Way(Topology{}.Opposite(w.Name))
+		if back == nil || back.To != w.From {
+			t.Fatalf("opposite way of %v: want way back to %v", w.Name, w.From.Pos)
+		}
+	}
+	for pos := range b.TileAt {
+		x, y := Topology{}.ToPixel(pos, 16, 8)
+		if got := (Topology{}).FromPixel(x, y, 16, 8); got != pos {
+			t.Fatalf("pixel of %v: want %v, got %v", pos, pos, got)
+		}
+	}
+	if got := (Topology{}).FromPixel(-1, 31, 16, 8); got != (tiled.Pos{-1, 3}) {
+		t.Fatalf("from pixel: want %v, got %v", tiled.Pos{-1, 3}, got)
+	}
+}
+
+func TestDiagonal(t *testing.T) {
+	topo := Topology{Diagonal: true, OrthogonalCost: 2, DiagonalCost: 3, CornerCut: tiled.NoCornerCut}
+	b := topo.NewBoard(3, 3)
+	if b.Kind != "quad8" || len(b.TileAt[tiled.Pos{1, 1}].Ways) != 8 {
+		t.Fatalf("diagonal board: want quad8 with 8 ways, got %v with %v", b.Kind, len(b.TileAt[tiled.Pos{1, 1}].Ways))
+	}
+	if w := b.TileAt[tiled.Pos{0, 0}].Way("SE"); w.Cost != 3 || len(w.Corners) != 2 {
+		t.Fatalf("diagonal way: want cost 3 with 2 corners, got %v with %v", w.Cost, len(w.Corners))
+	}
+	for i, dir := range b.Ways {
+		if got := b.Rotate(dirs["N"], i); got != dirs[dir] {
+			t.Fatalf("rotate north by %v: want %v, got %v", i, dirs[dir], got)
+		}
+	}
+	if got := b.Rotate(tiled.Pos{1, -2}, 1); got != (tiled.Pos{2, -1}) {
+		t.Fatalf("rotate: want %v, got %v", tiled.Pos{2, -1}, got)
+	}
+	if d := b.DirectionToward(tiled.Pos{0, 0}, tiled.Pos{2, 2}); d != "SE" {
+		t.Fatalf("direction toward: want SE, got %v", d)
+	}
+	c := &tiled.Character{RemainingPoints: 10}
+	c.Place(b.TileAt[tiled.Pos{0, 0}])
+	if !c.MoveTo(b.TileAt[tiled.Pos{2, 2}]) || c.SpentPoints != 6 {
+		t.Fatalf("move diagonally: want spent 6, got %v", c.SpentPoints)
+	}
+	cases := []struct {
+		cut     tiled.CornerCut
+		blocked []tiled.Pos
+		// removed are holes in the board.
+		removed []tiled.Pos
+		want    bool
+	}{
+		{tiled.NoCornerCut, nil, nil, true},
+		{tiled.NoCornerCut, []tiled.Pos{{1, 0}}, nil, false},
+		{tiled.CutOneCorner, []tiled.Pos{{1, 0}}, nil, true},
+		{tiled.CutOneCorner, []tiled.Pos{{1, 0}, {0, 1}}, nil, false},
+		{tiled.CutCorners, []tiled.Pos{{1, 0}, {0, 1}}, nil, true},
+		{tiled.NoCornerCut, nil, []tiled.Pos{{1, 0}}, false},
+		{tiled.CutOneCorner, []tiled.Pos{{0, 1}}, []tiled.Pos{{1, 0}}, false},
+	}
+	for _, cs := range cases {
+		b := Topology{Diagonal: true, CornerCut: cs.cut}.NewBoard(3, 3)
+		for _, p := range cs.blocked {
+			b.TileAt[p].Occupier = &tiled.Character{}
+		}
+		for _, p := range cs.removed {
+			delete(b.TileAt, p)
+		}
+		c := &tiled.Character{RemainingPoints: 1}
+		c.Place(b.TileAt[tiled.Pos{0, 0}])
+		if got := c.Step(*c.Tile().Way("SE")); got != cs.want {
+			t.Fatalf("step with corner cut %v blocked by %v and %v: want %v, got %v", cs.cut, cs.blocked, cs.removed, cs.want, got)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Command simulate runs AI versus AI battles without GUI, for balancing classes.
+//
+// eg) simulate -board hex -size 8 -n 1000 -a swordman,swordman -b spearman,spearman
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kybin/tiled"
+	"github.com/kybin/tiled/board/hex"
+	"github.com/kybin/tiled/board/quad"
+	"github.com/kybin/tiled/game/example/class"
+)
+
+var classes = map[string]func(ch *tiled.Character) *tiled.Class{
+	"swordman": class.NewSwordman,
+	"spearman": class.NewSpearman,
+}
+
+// basicAttack is the name of attacks by Character.AttackDirs in reports.
+const basicAttack = "(basic attack)"
+
+type config struct {
+	board     string
+	size      int
+	hp        int
+	power     int
+	points    int
+	maxTurns  int
+	seed      int64
+	partyA    []string
+	partyB    []string
+	evaluator []tiled.Evaluator
+}
+
+// result is a result of a battle.
+type result struct {
+	// winner is index of the party won the battle, -1 means a draw.
+	winner int
+	turns  int
+	// damage and casts are total damage and number of uses by class and skill name.
+	// eg) "swordman/knockback"
+	damage map[string]int
+	casts  map[string]int
+}
+
+func newBoard(kind string, size int) (*tiled.Board, error) {
+	switch kind {
+	case "quad":
+		return quad.NewBoard(size, size), nil
+	case "hex":
+		return hex.NewBoard(size, size), nil
+	}
+	return nil, fmt.Errorf("unknown board: %v", kind)
+}
+
+// newParty creates a party of the classes, placed on a column of the board.
+func newParty(cfg config, b *tiled.Board, classNames []string, column int) (*tiled.Party, error) {
+	p := &tiled.Party{NPC: true}
+	var tiles []*tiled.Tile
+	for _, t := range b.TileAt {
+		if t.Pos[0] == column {
+			tiles = append(tiles, t)
+		}
+	}
+	sort.Slice(tiles, func(i, j int) bool {
+		return tiles[i].Pos[1] < tiles[j].Pos[1]
+	})
+	if len(classNames) > len(tiles) {
+		return nil, fmt.Errorf("too many characters for the board: %v", len(classNames))
+	}
+	// spread characters along the column.
+	gap := len(tiles) / len(classNames)
+	for i, name := range classNames {
+		newClass := classes[name]
+		if newClass == nil {
+			return nil, fmt.Errorf("unknown class: %v", name)
+		}
+		c := &tiled.Character{
+			Party:           p,
+			HP:              cfg.hp,
+			AttackPower:     cfg.power,
+			MaxPoints:       cfg.points,
+			RemainingPoints: cfg.points,
+		}
+		c.Class = newClass(c)
+		c.Place(tiles[i*gap+gap/2])
+		p.Characters = append(p.Characters, c)
+	}
+	return p, nil
+}
+
+// skillName returns class and skill name the candidate used, or "" if it only moved.
+func skillName(cand *tiled.Candidate) string {
+	c := cand.Character
+	class := ""
+	if c.Class != nil {
+		class = c.Class.Name
+	}
+	if cand.Skill == nil {
+		if cand.Target != nil {
+			return class + "/" + basicAttack
+		}
+		return ""
+	}
+	skills := c.Skills
+	if skills == nil && c.Class != nil {
+		skills = c.Class.Skills
+	}
+	for name, sk := range skills {
+		if sk == cand.Skill {
+			return class + "/" + name
+		}
+	}
+	return ""
+}
+
+// defaultEvaluators returns evaluators characters of both parties use.
+// Distance to the nearest enemy outweighs risk taken, so characters close in
+// instead of waiting out of reach of each other until the battle draws.
+func defaultEvaluators(hp int) []tiled.Evaluator {
+	return []tiled.Evaluator{
+		tiled.DamageDealt{Weight: 1, KillBonus: float64(hp)},
+		tiled.RiskTaken{Weight: 0.2},
+		tiled.DistanceToObjective{Weight: 1},
+	}
+}
+
+func battle(cfg config, seed int64) (result, error) {
+	res := result{
+		winner: -1,
+		damage: make(map[string]int),
+		casts:  make(map[string]int),
+	}
+	b, err := newBoard(cfg.board, cfg.size)
+	if err != nil {
+		return res, err
+	}
+	a, err := newParty(cfg, b, cfg.partyA, 0)
+	if err != nil {
+		return res, err
+	}
+	e, err := newParty(cfg, b, cfg.partyB, cfg.size-1)
+	if err != nil {
+		return res, err
+	}
+	s := &tiled.Stage{
+		Board:           b,
+		Parties:         []*tiled.Party{a, e},
+		MaxTurns:        cfg.maxTurns,
+		Seed:            seed,
+		DefaultStrategy: &tiled.Strategy{Evaluators: cfg.evaluator},
+	}
+	s.Start()
+	for !s.Over() {
+		p := s.ActiveParty
+		for _, c := range p.Characters {
+			cand := s.DefaultStrategy.Action(s, c)
+			if cand == nil {
+				continue
+			}
+			name := skillName(cand)
+			if name == "" {
+				continue
+			}
+			res.casts[name]++
+			for _, q := range s.Parties {
+				if q == p {
+					continue
+				}
+				for _, ch := range q.Characters {
+					res.damage[name] += cand.HPLost(ch)
+				}
+			}
+		}
+		s.TurnOver()
+	}
+	// the turn goes over MaxTurns when the battle is a draw.
+	res.turns = s.CurrentTurn()
+	if cfg.maxTurns > 0 && res.turns > cfg.maxTurns {
+		res.turns = cfg.maxTurns
+	}
+	for i, p := range s.Parties {
+		if s.Win(p) {
+			res.winner = i
+		}
+	}
+	return res, nil
+}
+
+func main() {
+	cfg := config{}
+	var n, parallel int
+	var partyA, partyB string
+	flag.StringVar(&cfg.board, "board", "quad", "board kind, quad or hex")
+	flag.IntVar(&cfg.size, "size", 8, "width and height of the board")
+	flag.IntVar(&cfg.hp, "hp", 20, "hp of characters")
+	flag.IntVar(&cfg.power, "power", 6, "attack power of characters")
+	flag.IntVar(&cfg.points, "points", 3, "move points of characters per turn")
+	flag.IntVar(&cfg.maxTurns, "turns", 30, "max turns of a battle, it is a draw after that")
+	flag.Int64Var(&cfg.seed, "seed", 1, "seed of the first battle, next battles use next seeds")
+	flag.IntVar(&n, "n", 100, "number of battles")
+	flag.IntVar(&parallel, "parallel", runtime.NumCPU(), "number of battles run at the same time")
+	flag.StringVar(&partyA, "a", "swordman,swordman", "classes of party A, separated by comma")
+	flag.StringVar(&partyB, "b", "spearman,spearman", "classes of party B, separated by comma")
+	flag.Parse()
+	cfg.partyA = strings.Split(partyA, ",")
+	cfg.partyB = strings.Split(partyB, ",")
+	cfg.evaluator = defaultEvaluators(cfg.hp)
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	seeds := make(chan int64)
+	results := make(chan result)
+	errs := make(chan error, parallel)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range seeds {
+				res, err := battle(cfg, seed)
+				if err != nil {
+					errs <- err
+					return
+				}
+				results <- res
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < n; i++ {
+			seeds <- cfg.seed + int64(i)
+		}
+		close(seeds)
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	wins := make([]int, 3)
+	turns := 0
+	damage := make(map[string]int)
+	casts := make(map[string]int)
+	done := 0
+	for res := range results {
+		wins[res.winner+1]++
+		turns += res.turns
+		for name, d := range res.damage {
+			damage[name] += d
+		}
+		for name, c := range res.casts {
+			casts[name] += c
+		}
+		done++
+	}
+	select {
+	case err := <-errs:
+		log.Fatal(err)
+	default:
+	}
+	if done == 0 {
+		fmt.Fprintln(os.Stderr, "no battle")
+		os.Exit(1)
+	}
+	fmt.Printf("battles: %d on %dx%d %s board\n", done, cfg.size, cfg.size, cfg.board)
+	fmt.Printf("party A (%s) wins: %.1f%%\n", partyA, 100*float64(wins[1])/float64(done))
+	fmt.Printf("party B (%s) wins: %.1f%%\n", partyB, 100*float64(wins[2])/float64(done))
+	fmt.Printf("draws: %.1f%%\n", 100*float64(wins[0])/float64(done))
+	fmt.Printf("average turns: %.2f\n", float64(turns)/float64(done))
+	names := make([]string, 0, len(casts))
+	for name := range casts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("damage per skill:")
+	for _, name := range names {
+		fmt.Printf("  %-24s casts %6d, damage %8d, per cast %.2f\n", name, casts[name], damage[name], float64(damage[name])/float64(casts[name]))
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBattle(t *testing.T) {
+	for _, board := range []string{"quad", "hex"} {
+		cfg := config{
+			board:    board,
+			size:     8,
+			hp:       20,
+			power:    6,
+			points:   3,
+			maxTurns: 30,
+			partyA:   []string{"swordman", "swordman"},
+			partyB:   []string{"swordman", "swordman"},
+		}
+		cfg.evaluator = defaultEvaluators(cfg.hp)
+		res, err := battle(cfg, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.turns > cfg.maxTurns {
+			t.Fatalf("%v: turns: want at most %v, got %v", board, cfg.maxTurns, res.turns)
+		}
+		if res.winner == -1 {
+			t.Fatalf("%v: swordman versus swordman should not draw", board)
+		}
+		total := 0
+		for name, d := range res.damage {
+			if res.casts[name] == 0 {
+				t.Fatalf("%v: damage by %v which is never cast", board, name)
+			}
+			if d < 0 {
+				t.Fatalf("%v: damage by %v: want at least 0, got %v", board, name, d)
+			}
+			total += d
+		}
+		for name := range res.casts {
+			if !strings.HasPrefix(name, "swordman/") {
+				t.Fatalf("%v: casts should be keyed by class and skill, got %v", board, name)
+			}
+		}
+		// the losing party lost all of its hp.
+		if want := len(cfg.partyB) * cfg.hp; total < want {
+			t.Fatalf("%v: total damage: want at least %v, got %v", board, want, total)
+		}
+		again, err := battle(cfg, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.winner != res.winner || again.turns != res.turns {
+			t.Fatalf("%v: battles of the same seed should end the same", board)
+		}
+	}
+	cfg := config{
+		board:    "quad",
+		size:     8,
+		hp:       1000,
+		power:    1,
+		points:   3,
+		maxTurns: 5,
+		partyA:   []string{"swordman"},
+		partyB:   []string{"swordman"},
+	}
+	cfg.evaluator = defaultEvaluators(cfg.hp)
+	res, err := battle(cfg, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.winner != -1 || res.turns != cfg.maxTurns {
+		t.Fatalf("draw: want -1 after %v turns, got %v after %v turns", cfg.maxTurns, res.winner, res.turns)
+	}
+}
@@ -0,0 +1,327 @@
+package tiled
+
+import "time"
+
+// Command is a reversible action of a character in a turn.
+type Command interface {
+	// Do does the command. It reports whether the command is done.
+	// Do is called again to redo the command after Undo.
+	Do() bool
+	// Undo reverts what Do did.
+	Undo()
+}
+
+// Do does the command and records it, so it could be undone until the next Commit.
+// Doing a new command drops commands undone before.
+func (s *Stage) Do(cmd Command) bool {
+	if !cmd.Do() {
+		return false
+	}
+	s.history = append(s.history, cmd)
+	s.undone = nil
+	return true
+}
+
+// Undo undoes the last command. It reports whether there was a command to undo.
+func (s *Stage) Undo() bool {
+	if len(s.history) == 0 {
+		return false
+	}
+	cmd := s.history[len(s.history)-1]
+	s.history = s.history[:len(s.history)-1]
+	cmd.Undo()
+	s.undone = append(s.undone, cmd)
+	s.publish(Undone{})
+	return true
+}
+
+// Redo redoes the last undone command. It reports whether there was a command to redo.
+func (s *Stage) Redo() bool {
+	if len(s.undone) == 0 {
+		return false
+	}
+	cmd := s.undone[len(s.undone)-1]
+	s.undone = s.undone[:len(s.undone)-1]
+	if !cmd.Do() {
+		s.undone = nil
+		return false
+	}
+	s.history = append(s.history, cmd)
+	s.publish(Redone{})
+	return true
+}
+
+// Commit makes commands done so far cannot be undone.
+// Characters in the stage commit their actions as well.
+func (s *Stage) Commit() {
+	s.history = nil
+	s.undone = nil
+	for _, p := range s.Parties {
+		for _, c := range p.Characters {
+			c.Commit()
+		}
+	}
+}
+
+// Step steps the character through the way as a command.
+func (s *Stage) Step(c *Character, w Way) bool {
+	return s.Do(s.NewCommand(func() bool {
+		n := len(c.Moves)
+		return s.moved(c, n, c.Step(w))
+	}))
+}
+
+// MoveTo moves the character to the tile as a command.
+func (s *Stage) MoveTo(c *Character, t *Tile) bool {
+	return s.Do(s.NewCommand(func() bool {
+		n := len(c.Moves)
+		return s.moved(c, n, c.MoveTo(t))
+	}))
+}
+
+// moved publishes moves of the character from its nth move, if ok.
+// It returns ok.
+func (s *Stage) moved(c *Character, n int, ok bool) bool {
+	if !ok {
+		return false
+	}
+	for _, w := range c.Moves[n:] {
+		s.publish(Moved{Character: c, From: w.From, To: w.To})
+	}
+	s.remember(c.Party)
+	return true
+}
+
+// Attack lets the character attack the tile as a command.
+// The damage is modified by the side it attacks from, see DamageFrom.
+func (s *Stage) Attack(c *Character, t *Tile) bool {
+	return s.Do(s.NewCommand(func() bool {
+		return s.attack(c, t)
+	}))
+}
+
+func (s *Stage) attack(c *Character, t *Tile) bool {
+	if !c.canAttack(t) {
+		return false
+	}
+	c.Face(t.Pos)
+	s.watchHP(c, func() {
+		t.Occupier.HP -= s.DamageFrom(c, t.Occupier, c.Stats().AttackPower)
+	})
+	return true
+}
+
+// Cast casts the skill to sel as a command. The events of the skill are resolved
+// by the stage. It does nothing when sel is not selectable.
+// The caster, who is on the origin of the skill, turns toward sel.
+func (s *Stage) Cast(sk Skill, sel Pos) bool {
+	if !Selectable(sk, sel) {
+		return false
+	}
+	return s.Do(s.NewCommand(func() bool {
+		s.cast(sk, sel)
+		return true
+	}))
+}
+
+func (s *Stage) cast(sk Skill, sel Pos) {
+	c := sk.Origin().Occupier
+	if c != nil {
+		c.Face(sel)
+	}
+	s.publish(SkillCast{Caster: c, Skill: sk, Sel: sel})
+	if as, ok := sk.(AnimatedSkill); ok && c != nil && s.simulating == 0 {
+		a := as.Action()
+		if a != nil && hasFrameEvent(a, HitFrame) {
+			a.resolveOn = HitFrame
+			a.resolving = sk.Cast(sel)
+			a.stage = s
+			c.PendingActions = append(c.PendingActions, a)
+			return
+		}
+	}
+	s.Resolve(sk.Cast(sel))
+}
+
+func hasFrameEvent(a *Action, name string) bool {
+	for _, f := range a.Animation.Frames {
+		if f.Event == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Use lets the character use the consumable as a command.
+// The effect of the consumable is resolved by the stage.
+func (s *Stage) Use(c *Character, cs *Consumable) bool {
+	return s.Do(s.NewCommand(func() bool {
+		ev, ok := c.Use(cs)
+		if !ok {
+			return false
+		}
+		s.Resolve([]CharacterEvent{ev})
+		return true
+	}))
+}
+
+// NewCommand creates a command doing fn, which reports whether it did something.
+// The command reverts every character and tile of the stage on Undo,
+// so fn can change them freely.
+func (s *Stage) NewCommand(fn func() bool) Command {
+	return &stageCommand{stage: s, fn: fn}
+}
+
+type stageCommand struct {
+	stage  *Stage
+	fn     func() bool
+	before *stageSnapshot
+	after  *stageSnapshot
+}
+
+func (c *stageCommand) Do() bool {
+	if c.after != nil {
+		// redo
+		c.after.restore()
+		return true
+	}
+	before := c.stage.snapshot()
+	if !c.fn() {
+		before.restore()
+		return false
+	}
+	c.before = before
+	c.after = c.stage.snapshot()
+	return true
+}
+
+func (c *stageCommand) Undo() {
+	c.before.restore()
+}
+
+// stageSnapshot saves states of characters and tiles in a stage that could be
+// changed by an action.
+type stageSnapshot struct {
+	chars     map[*Character]characterSnapshot
+	occupier  map[*Tile]*Character
+	count     map[*Consumable]int
+	relations map[*Party]map[*Party]Relation
+	timeline  *Timeline
+	ct        map[*Character]int
+	// actions are progress of pending actions, which goes on after the snapshot.
+	actions map[*Action]actionSnapshot
+}
+
+type actionSnapshot struct {
+	Age       time.Duration
+	resolving []CharacterEvent
+}
+
+type characterSnapshot struct {
+	Origin          *Tile
+	Moves           []Way
+	RemainingPoints int
+	SpentPoints     int
+	HP              int
+	States          map[string]State
+	Facing          string
+	// PendingActions could have events of an animated skill not resolved yet.
+	PendingActions []*Action
+}
+
+func (s *Stage) snapshot() *stageSnapshot {
+	ss := &stageSnapshot{
+		chars:     make(map[*Character]characterSnapshot),
+		occupier:  make(map[*Tile]*Character),
+		count:     make(map[*Consumable]int),
+		relations: make(map[*Party]map[*Party]Relation),
+		actions:   make(map[*Action]actionSnapshot),
+	}
+	for _, p := range s.Parties {
+		rel := make(map[*Party]Relation, len(p.Relations))
+		for q, r := range p.Relations {
+			rel[q] = r
+		}
+		ss.relations[p] = rel
+	}
+	if s.Timeline != nil {
+		ss.timeline = s.Timeline
+		ss.ct = make(map[*Character]int, len(s.Timeline.ct))
+		for c, v := range s.Timeline.ct {
+			ss.ct[c] = v
+		}
+	}
+	for _, p := range s.Parties {
+		for _, c := range p.Characters {
+			cs := characterSnapshot{
+				Origin:          c.Origin,
+				Moves:           append([]Way(nil), c.Moves...),
+				RemainingPoints: c.RemainingPoints,
+				SpentPoints:     c.SpentPoints,
+				HP:              c.HP,
+				Facing:          c.Facing,
+				PendingActions:  append([]*Action(nil), c.PendingActions...),
+			}
+			if c.States != nil {
+				cs.States = make(map[string]State, len(c.States))
+				for k, v := range c.States {
+					cs.States[k] = *v
+				}
+			}
+			for _, cs := range c.Consumables {
+				ss.count[cs] = cs.Count
+			}
+			for _, a := range c.PendingActions {
+				ss.actions[a] = actionSnapshot{Age: a.Age, resolving: a.resolving}
+			}
+			ss.chars[c] = cs
+		}
+	}
+	if s.Board != nil {
+		for _, t := range s.Board.TileAt {
+			ss.occupier[t] = t.Occupier
+		}
+	}
+	return ss
+}
+
+func (ss *stageSnapshot) restore() {
+	for c, cs := range ss.chars {
+		c.Origin = cs.Origin
+		c.Moves = append([]Way(nil), cs.Moves...)
+		c.RemainingPoints = cs.RemainingPoints
+		c.SpentPoints = cs.SpentPoints
+		c.HP = cs.HP
+		c.Facing = cs.Facing
+		c.PendingActions = append([]*Action(nil), cs.PendingActions...)
+		c.States = nil
+		if cs.States != nil {
+			c.States = make(map[string]*State, len(cs.States))
+			for k, v := range cs.States {
+				c.States[k] = &v
+			}
+		}
+	}
+	for t, o := range ss.occupier {
+		t.Occupier = o
+	}
+	for cs, n := range ss.count {
+		cs.Count = n
+	}
+	for a, as := range ss.actions {
+		a.Age = as.Age
+		a.resolving = as.resolving
+	}
+	if ss.timeline != nil {
+		ss.timeline.ct = make(map[*Character]int, len(ss.ct))
+		for c, v := range ss.ct {
+			ss.timeline.ct[c] = v
+		}
+	}
+	for p, rel := range ss.relations {
+		p.Relations = make(map[*Party]Relation, len(rel))
+		for q, r := range rel {
+			p.Relations[q] = r
+		}
+	}
+}
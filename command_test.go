@@ -0,0 +1,62 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func TestUndoRedo(t *testing.T) {
+	b := newTestBoard([]string{
+		"....",
+	})
+	a, e := newTestParty(10), newTestParty(10)
+	s := &Stage{Board: b, Parties: []*Party{a, e}}
+	c := a.Characters[0]
+	c.Origin = b.TileAt[Pos{0, 0}]
+	c.Origin.Occupier = c
+	c.RemainingPoints = 3
+	c.AttackPower = 4
+	c.AttackDirs = [][]string{{"E"}}
+	target := e.Characters[0]
+	target.Origin = b.TileAt[Pos{3, 0}]
+	target.Origin.Occupier = target
+
+	if !s.MoveTo(c, b.TileAt[Pos{2, 0}]) {
+		t.Fatalf("move: want true, got false")
+	}
+	if !s.Attack(c, b.TileAt[Pos{3, 0}]) {
+		t.Fatalf("attack: want true, got false")
+	}
+	if s.Attack(c, b.TileAt[Pos{1, 0}]) {
+		t.Fatalf("attack on an empty tile: want false, got true")
+	}
+	if target.HP != 6 {
+		t.Fatalf("target hp: want 6, got %v", target.HP)
+	}
+	if !s.Undo() {
+		t.Fatalf("undo attack: want true, got false")
+	}
+	if target.HP != 10 {
+		t.Fatalf("target hp after undo: want 10, got %v", target.HP)
+	}
+	if !s.Undo() {
+		t.Fatalf("undo move: want true, got false")
+	}
+	if c.Tile().Pos != (Pos{0, 0}) || c.SpentPoints != 0 || b.TileAt[Pos{2, 0}].Occupier != nil {
+		t.Fatalf("move is not undone")
+	}
+	if s.Undo() {
+		t.Fatalf("undo without history: want false, got true")
+	}
+	s.Redo()
+	s.Redo()
+	if c.Tile().Pos != (Pos{2, 0}) || target.HP != 6 || c.SpentPoints != 2 {
+		t.Fatalf("commands are not redone")
+	}
+	s.Commit()
+	if s.Undo() {
+		t.Fatalf("undo after commit: want false, got true")
+	}
+	if c.RemainingPoints != 1 || c.Origin.Pos != (Pos{2, 0}) {
+		t.Fatalf("commit should be applied to the character")
+	}
+}
@@ -0,0 +1,50 @@
+package tiled
+
+// Relation is how a party regards another party.
+type Relation int
+
+const (
+	// Hostile parties fight each other. Parties are hostile to each other unless set.
+	Hostile = Relation(iota)
+	// Neutral parties are neither attacked nor protected.
+	Neutral
+	// Ally parties fight together.
+	Ally
+)
+
+// Relation returns how the party regards q. A party is an ally to itself.
+func (p *Party) Relation(q *Party) Relation {
+	if p == q {
+		return Ally
+	}
+	return p.Relations[q]
+}
+
+// SetRelation sets how the party regards q. It doesn't change how q regards the party.
+// It could be changed while a stage is going on. eg. defection, surrender
+func (p *Party) SetRelation(q *Party, r Relation) {
+	if p == q {
+		return
+	}
+	if p.Relations == nil {
+		p.Relations = make(map[*Party]Relation)
+	}
+	p.Relations[q] = r
+}
+
+// SetMutualRelation sets how the two parties regard each other.
+func SetMutualRelation(p, q *Party, r Relation) {
+	p.SetRelation(q, r)
+	q.SetRelation(p, r)
+}
+
+// IsAlly reports whether the party regards q as an ally.
+func (p *Party) IsAlly(q *Party) bool {
+	return p.Relation(q) == Ally
+}
+
+// IsHostile reports whether the party regards q as an enemy.
+// Characters only attack or target characters of hostile parties.
+func (p *Party) IsHostile(q *Party) bool {
+	return p.Relation(q) == Hostile
+}
@@ -0,0 +1,48 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func TestRelations(t *testing.T) {
+	b := newTestBoard([]string{
+		"...",
+	})
+	a, n := newTestParty(10), newTestParty(10)
+	c, en := a.Characters[0], n.Characters[0]
+	c.AttackPower = 3
+	c.AttackDirs = [][]string{{"E"}}
+	c.Place(b.TileAt[Pos{0, 0}])
+	en.Place(b.TileAt[Pos{1, 0}])
+	s := &Stage{Board: b, Parties: []*Party{a, n}}
+
+	if !a.IsAlly(a) || !a.IsHostile(n) {
+		t.Fatalf("parties should be hostile to others, and ally to themselves")
+	}
+	// n regards a neutral, but a still regards n hostile.
+	n.SetRelation(a, Neutral)
+	if a.Relation(n) != Hostile || n.Relation(a) != Neutral {
+		t.Fatalf("relation should be asymmetric")
+	}
+	if !(EliminateEnemies{}).Achieved(s, n) {
+		t.Fatalf("neutral party has no enemy to eliminate")
+	}
+	SetMutualRelation(a, n, Ally)
+	if s.Attack(c, en.Tile()) {
+		t.Fatalf("should not attack an ally")
+	}
+	// defection
+	s.Do(s.NewCommand(func() bool {
+		n.SetRelation(a, Hostile)
+		a.SetRelation(n, Hostile)
+		return true
+	}))
+	if !s.Attack(c, en.Tile()) || en.HP != 7 {
+		t.Fatalf("should attack a defected party")
+	}
+	s.Undo()
+	s.Undo()
+	if !a.IsAlly(n) || !n.IsAlly(a) {
+		t.Fatalf("undo should restore relations")
+	}
+}
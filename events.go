@@ -0,0 +1,190 @@
+package tiled
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a change of a stage or a world published by its Bus.
+// It is one of the event types below. Subscribers switch on the type.
+type Event interface{}
+
+// Moved is published when a character moved from a tile to another.
+type Moved struct {
+	Character *Character
+	From      *Tile
+	To        *Tile
+	// Forced reports whether the character was forced to move. eg. knockback
+	Forced bool
+}
+
+// Fell is published when a character was forced over a ledge, from a tile to another.
+type Fell struct {
+	Character *Character
+	Source    *Character
+	From      *Tile
+	To        *Tile
+}
+
+// EnteredHazard is published when a character was forced onto a tile having a hazard.
+type EnteredHazard struct {
+	Character *Character
+	Source    *Character
+	Tile      *Tile
+	Hazard    string
+}
+
+// Damaged is published when HP of a character is reduced.
+type Damaged struct {
+	Character *Character
+	// Source is the character caused the damage. It could be nil. eg. bleed
+	Source *Character
+	Amount int
+}
+
+// Healed is published when HP of a character is increased.
+type Healed struct {
+	Character *Character
+	Source    *Character
+	Amount    int
+}
+
+// Evaded is published when a character evaded damage from the source.
+type Evaded struct {
+	Character *Character
+	Source    *Character
+}
+
+// Died is published when a character is dead.
+type Died struct {
+	Character *Character
+	Source    *Character
+}
+
+// TurnStarted is published when a party, or a character with Timeline, starts its turn.
+type TurnStarted struct {
+	Turn      int
+	Party     *Party
+	Character *Character
+}
+
+// SkillCast is published when a character cast a skill, before the skill is resolved.
+type SkillCast struct {
+	Caster *Character
+	Skill  Skill
+	Sel    Pos
+}
+
+// Undone and Redone are published when a command is undone or redone.
+// Subscribers should read the stage again, as anything could be changed.
+type Undone struct{}
+type Redone struct{}
+
+// Ticked is published when time of a world passed.
+type Ticked struct {
+	Time  time.Time
+	Delta time.Duration
+}
+
+// Bus delivers events to its subscribers synchronously, in the order they subscribed.
+// The zero value is ready to use.
+type Bus struct {
+	mu   sync.Mutex
+	next int
+	subs []subscriber
+}
+
+type subscriber struct {
+	id int
+	fn func(ev Event)
+}
+
+// Subscribe lets fn receive events published after. It returns a function to unsubscribe.
+func (b *Bus) Subscribe(fn func(ev Event)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.next
+	b.next++
+	b.subs = append(b.subs, subscriber{id: id, fn: fn})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subs {
+			if s.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers the event to subscribers.
+func (b *Bus) Publish(ev Event) {
+	b.mu.Lock()
+	subs := b.subs
+	b.mu.Unlock()
+	for _, s := range subs {
+		s.fn(ev)
+	}
+}
+
+// publish publishes the event on the stage's bus, unless the stage is simulating.
+func (s *Stage) publish(ev Event) {
+	if s.simulating > 0 {
+		return
+	}
+	s.Events.Publish(ev)
+}
+
+// watchHP runs fn, then publishes changes of HP of characters in the stage as caused by src.
+// Changes inside of another watchHP are published by the outer one.
+func (s *Stage) watchHP(src *Character, fn func()) {
+	if s.watching {
+		fn()
+		return
+	}
+	s.watching = true
+	hp := make(map[*Character]int)
+	for _, p := range s.Parties {
+		for _, c := range p.Characters {
+			hp[c] = c.HP
+		}
+	}
+	fn()
+	s.watching = false
+	for _, p := range s.Parties {
+		for _, c := range p.Characters {
+			s.publishHP(c, src, hp[c])
+		}
+	}
+}
+
+// publishHP publishes events of the character whose HP was hp.
+func (s *Stage) publishHP(c, src *Character, hp int) {
+	for _, ev := range hpEvents(c, src, hp) {
+		s.publish(ev)
+	}
+}
+
+// hpEvents returns events of the character's HP changed from hp, as caused by src.
+func hpEvents(c, src *Character, hp int) []Event {
+	events := make([]Event, 0)
+	switch {
+	case c.HP < hp:
+		events = append(events, Damaged{Character: c, Source: src, Amount: hp - c.HP})
+	case c.HP > hp:
+		events = append(events, Healed{Character: c, Source: src, Amount: c.HP - hp})
+	}
+	if hp > 0 && c.Dead() {
+		events = append(events, Died{Character: c, Source: src})
+	}
+	return events
+}
+
+// publishTurn publishes the start of the current turn.
+func (s *Stage) publishTurn() {
+	if s.ActiveParty == nil {
+		return
+	}
+	s.publish(TurnStarted{Turn: s.turn, Party: s.ActiveParty, Character: s.ActiveCharacter})
+}
@@ -0,0 +1,59 @@
+package tiled
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	b := newTestBoard([]string{
+		"....",
+	})
+	a, e := newTestParty(10), newTestParty(3)
+	c, en := a.Characters[0], e.Characters[0]
+	c.MaxPoints, c.RemainingPoints, c.AttackPower = 3, 3, 3
+	c.AttackDirs = [][]string{{"E"}}
+	c.Place(b.TileAt[Pos{0, 0}])
+	en.Place(b.TileAt[Pos{3, 0}])
+	en.AttackPower = 2
+	en.AttackDirs = [][]string{{"W"}}
+	e.Strategy = NewStrategy(0, DamageDealt{Weight: 1})
+	s := &Stage{Board: b, Parties: []*Party{a, e}}
+	events := make([]Event, 0)
+	unsubscribe := s.Events.Subscribe(func(ev Event) {
+		events = append(events, ev)
+	})
+	s.Start()
+	s.MoveTo(c, b.TileAt[Pos{2, 0}])
+	s.Attack(c, en.Tile())
+	s.Undo()
+	c.AddState(Bleed(1, 1))
+	s.TurnOver()
+	want := []Event{
+		TurnStarted{Turn: 1, Party: a},
+		Moved{Character: c, From: b.TileAt[Pos{0, 0}], To: b.TileAt[Pos{1, 0}]},
+		Moved{Character: c, From: b.TileAt[Pos{1, 0}], To: b.TileAt[Pos{2, 0}]},
+		Damaged{Character: en, Source: c, Amount: 3},
+		Died{Character: en, Source: c},
+		Undone{},
+		Damaged{Character: c, Amount: 1},
+		TurnStarted{Turn: 1, Party: e},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events: want %v, got %v", want, events)
+	}
+	// simulation of AI is not published.
+	events = events[:0]
+	e.AutoAction(s)
+	want = []Event{
+		Damaged{Character: c, Source: en, Amount: 2},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events of AI: want %v, got %v", want, events)
+	}
+	unsubscribe()
+	s.TurnOver()
+	if len(events) != 1 {
+		t.Fatalf("unsubscribed function should not receive events")
+	}
+}
@@ -0,0 +1,104 @@
+package tiled
+
+import (
+	"math"
+)
+
+// Side is a side of a character where an attack comes from.
+type Side int
+
+const (
+	Front = Side(iota)
+	Flank
+	Back
+)
+
+// DirectionToward returns name of the way pointing toward the position to, from the position from.
+// It returns an empty string when they are the same position, or the board cannot rotate.
+func (b *Board) DirectionToward(from, to Pos) string {
+	if from == to || len(b.Ways) == 0 || b.Rotate == nil {
+		return ""
+	}
+	return b.Ways[b.RotationToward(from, to)%len(b.Ways)]
+}
+
+// Face turns the character toward the position.
+// It doesn't turn when the character is on the position.
+func (c *Character) Face(p Pos) {
+	t := c.Tile()
+	if t == nil {
+		return
+	}
+	if dir := t.Board.DirectionToward(t.Pos, p); dir != "" {
+		c.Facing = dir
+	}
+}
+
+// SideOf returns the side of the character facing the position.
+// Everything is in front of a character not facing anywhere.
+func (c *Character) SideOf(p Pos) Side {
+	t := c.Tile()
+	if t == nil || c.Facing == "" {
+		return Front
+	}
+	ways := t.Board.Ways
+	dir := t.Board.DirectionToward(t.Pos, p)
+	fi, di := -1, -1
+	for i, w := range ways {
+		if w == c.Facing {
+			fi = i
+		}
+		if w == dir {
+			di = i
+		}
+	}
+	if fi < 0 || di < 0 {
+		return Front
+	}
+	// steps between the two directions, around the board's ways.
+	n := len(ways)
+	k := ((di-fi)%n + n) % n
+	if n-k < k {
+		k = n - k
+	}
+	switch {
+	case k == 0:
+		return Front
+	case 2*k == n:
+		return Back
+	}
+	return Flank
+}
+
+// DamageFrom returns the damage scaled by the side of dst src attacks from,
+// then reduced by defense of dst. It is never negative.
+// dst evades the damage by its evasion, rolled with Rand. While the stage is simulating,
+// the damage is reduced by the evasion instead of rolling, so simulations don't change random numbers.
+func (s *Stage) DamageFrom(src, dst *Character, dmg int) int {
+	st := dst.Stats()
+	if st.Evasion > 0 {
+		if s.simulating > 0 {
+			dmg = dmg * max(100-st.Evasion, 0) / 100
+		} else if s.Rand().Intn(100) < st.Evasion {
+			s.publish(Evaded{Character: dst, Source: src})
+			return 0
+		}
+	}
+	if src != nil && src.Tile() != nil {
+		m := 1.0
+		switch dst.SideOf(src.Tile().Pos) {
+		case Flank:
+			m = s.FlankDamage
+		case Back:
+			m = s.BackDamage
+		}
+		if m != 0 {
+			dmg = int(math.Round(float64(dmg) * m))
+		}
+	}
+	dmg -= st.Defense
+	if dmg < 0 {
+		dmg = 0
+	}
+	return dmg
+}
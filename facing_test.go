@@ -0,0 +1,52 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func TestFacing(t *testing.T) {
+	b := newTestBoard([]string{
+		"...",
+		"...",
+		"...",
+	})
+	a, e := newTestParty(10), newTestParty(10)
+	c := a.Characters[0]
+	c.Place(b.TileAt[Pos{0, 1}])
+	c.RemainingPoints = 10
+	c.AttackPower = 4
+	c.AttackDirs = [][]string{{"N"}, {"E"}, {"S"}, {"W"}}
+	en := e.Characters[0]
+	en.Place(b.TileAt[Pos{1, 1}])
+	en.Facing = "E"
+	s := &Stage{Board: b, Parties: []*Party{a, e}, FlankDamage: 1.5, BackDamage: 2}
+
+	if en.SideOf(Pos{2, 1}) != Front || en.SideOf(Pos{1, 0}) != Flank || en.SideOf(Pos{0, 1}) != Back {
+		t.Fatalf("wrong sides of a character facing E")
+	}
+	if !s.Attack(c, en.Tile()) || en.HP != 2 {
+		t.Fatalf("attack from back: want hp 2, got %v", en.HP)
+	}
+	if c.Facing != "E" {
+		t.Fatalf("attacker should face the target: want E, got %v", c.Facing)
+	}
+	s.Undo()
+	if c.Facing != "" {
+		t.Fatalf("undo should restore facing: got %v", c.Facing)
+	}
+	c.Step(*c.Tile().Way("N"))
+	if c.Facing != "N" {
+		t.Fatalf("step should turn the character: want N, got %v", c.Facing)
+	}
+	c.Step(*c.Tile().Way("E"))
+	if !s.Attack(c, en.Tile()) || en.HP != 4 {
+		t.Fatalf("attack from flank: want hp 4, got %v", en.HP)
+	}
+	if c.Facing != "S" {
+		t.Fatalf("attacker should face the target: want S, got %v", c.Facing)
+	}
+	// diagonal positions are toward the nearest way, ties go to the first one.
+	if d := b.DirectionToward(Pos{1, 1}, Pos{2, 0}); d != "N" {
+		t.Fatalf("direction toward NE: want N, got %v", d)
+	}
+}
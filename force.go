@@ -0,0 +1,109 @@
+package tiled
+
+// Forced movement moves characters regardless of their points, eg. by knockback.
+// It resolves events caused by the movement on the stage, and returns events applied.
+//
+// Events caused are:
+//
+//	"collide": the character is blocked by a wall, the board edge, impassable terrain or another character.
+//	           Both the character and the one blocking take Stage.CollisionDamage.
+//	"fall":    the character passed a ledge way.
+//	"hazard":  the character entered a tile having a hazard, see Tile.Hazard.
+//	           Forced movement stops at the hazard.
+//
+// Source of the events is the character forced the movement.
+// Falls and hazards are also published as Fell and EnteredHazard, after Moved.
+
+// Push pushes the character up to n steps through ways named dir.
+func (s *Stage) Push(src, c *Character, dir string, n int) []CharacterEvent {
+	return s.force(src, c, n, func(at *Tile) string {
+		return dir
+	})
+}
+
+// Pull pulls the character up to n steps toward src.
+// It stops next to src without collision.
+func (s *Stage) Pull(src, c *Character, n int) []CharacterEvent {
+	return s.force(src, c, n, func(at *Tile) string {
+		return at.Board.DirectionToward(at.Pos, src.Tile().Pos)
+	})
+}
+
+// Swap swaps tiles of the two characters.
+// It doesn't swap when either of them cannot stand on the other's tile. eg. a walker onto a lake
+func (s *Stage) Swap(src, a, b *Character) []CharacterEvent {
+	ta, tb := a.Tile(), b.Tile()
+	if ta == nil || tb == nil || !a.canStand(tb) || !b.canStand(ta) {
+		return nil
+	}
+	ta.Occupier = nil
+	tb.Occupier = nil
+	a.Place(tb)
+	b.Place(ta)
+	s.publish(Moved{Character: a, From: ta, To: tb, Forced: true})
+	s.publish(Moved{Character: b, From: tb, To: ta, Forced: true})
+	events := make([]CharacterEvent, 0)
+	for _, c := range []*Character{a, b} {
+		if c.Tile().Hazard != "" {
+			events = append(events, s.enterHazard(src, c))
+		}
+	}
+	return s.Resolve(events)
+}
+
+// force moves the character up to n steps through ways named by dir at each tile.
+func (s *Stage) force(src, c *Character, n int, dir func(at *Tile) string) []CharacterEvent {
+	events := make([]CharacterEvent, 0)
+	for i := 0; i < n; i++ {
+		at := c.Tile()
+		if at == nil {
+			break
+		}
+		name := dir(at)
+		if name == "" {
+			break
+		}
+		w := at.Way(name)
+		if w != nil && src != nil && w.To.Occupier == src {
+			// pulled next to src
+			break
+		}
+		if w == nil || !c.canEnter(w.To) {
+			events = append(events, s.collide(src, c))
+			if w != nil && w.To.Occupier != nil {
+				events = append(events, s.collide(src, w.To.Occupier))
+			}
+			break
+		}
+		c.Place(w.To)
+		s.publish(Moved{Character: c, From: at, To: w.To, Forced: true})
+		if w.Ledge {
+			s.publish(Fell{Character: c, Source: src, From: at, To: w.To})
+			events = append(events, CharacterEvent{Character: c, Source: src, On: "fall"})
+		}
+		if w.To.Hazard != "" {
+			events = append(events, s.enterHazard(src, c))
+			break
+		}
+	}
+	return s.Resolve(events)
+}
+
+// enterHazard publishes the character entered the hazard of its tile, and returns the event of it.
+func (s *Stage) enterHazard(src, c *Character) CharacterEvent {
+	t := c.Tile()
+	s.publish(EnteredHazard{Character: c, Source: src, Tile: t, Hazard: t.Hazard})
+	return CharacterEvent{Character: c, Source: src, On: "hazard"}
+}
+
+func (s *Stage) collide(src, c *Character) CharacterEvent {
+	return CharacterEvent{
+		Character: c,
+		Source:    src,
+		On:        "collide",
+		Value:     s.CollisionDamage,
+		Effect: func(ev *CharacterEvent) {
+			ev.Character.HP -= ev.Value
+		},
+	}
+}
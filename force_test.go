@@ -0,0 +1,80 @@
+package tiled
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestForcedMovement(t *testing.T) {
+	b := newTestBoard([]string{
+		".....",
+		".....",
+	})
+	a, e := newTestParty(10), newTestParty(10, 10)
+	src := a.Characters[0]
+	c, d := e.Characters[0], e.Characters[1]
+	src.Place(b.TileAt[Pos{0, 0}])
+	c.Place(b.TileAt[Pos{1, 0}])
+	d.Place(b.TileAt[Pos{4, 0}])
+	s := &Stage{Board: b, Parties: []*Party{a, e}, CollisionDamage: 2}
+
+	evs := s.Push(src, c, "E", 5)
+	if c.Tile().Pos != (Pos{3, 0}) {
+		t.Fatalf("push: want %v, got %v", Pos{3, 0}, c.Tile().Pos)
+	}
+	if len(evs) != 2 || c.HP != 8 || d.HP != 8 {
+		t.Fatalf("collision with a character: want both hp 8, got %v and %v", c.HP, d.HP)
+	}
+	s.Push(src, c, "N", 1)
+	if c.Tile().Pos != (Pos{3, 0}) || c.HP != 6 || src.HP != 10 {
+		t.Fatalf("collision with the board edge: want hp 6 at %v, got %v at %v", Pos{3, 0}, c.HP, c.Tile().Pos)
+	}
+
+	s.Pull(src, c, 5)
+	if c.Tile().Pos != (Pos{1, 0}) || c.HP != 6 {
+		t.Fatalf("pull: want hp 6 at %v, got %v at %v", Pos{1, 0}, c.HP, c.Tile().Pos)
+	}
+
+	b.TileAt[Pos{1, 0}].Way("S").Ledge = true
+	b.TileAt[Pos{1, 1}].Hazard = "lava"
+	published := make([]Event, 0)
+	unsubscribe := s.Events.Subscribe(func(ev Event) {
+		published = append(published, ev)
+	})
+	evs = s.Push(src, c, "S", 2)
+	unsubscribe()
+	if len(evs) != 2 || evs[0].On != "fall" || evs[1].On != "hazard" {
+		t.Fatalf("want fall and hazard events, got %v", evs)
+	}
+	from, to := b.TileAt[Pos{1, 0}], b.TileAt[Pos{1, 1}]
+	want := []Event{
+		Moved{Character: c, From: from, To: to, Forced: true},
+		Fell{Character: c, Source: src, From: from, To: to},
+		EnteredHazard{Character: c, Source: src, Tile: to, Hazard: "lava"},
+	}
+	if !reflect.DeepEqual(published, want) {
+		t.Fatalf("published: want %v, got %v", want, published)
+	}
+	if c.Tile().Pos != (Pos{1, 1}) {
+		t.Fatalf("push over the ledge: want %v, got %v", Pos{1, 1}, c.Tile().Pos)
+	}
+	c.RemainingPoints = 10
+	c.Place(b.TileAt[Pos{1, 0}])
+	if c.Step(*c.Tile().Way("S")) {
+		t.Fatalf("ledge should not be stepped")
+	}
+
+	s.Swap(src, src, d)
+	if src.Tile().Pos != (Pos{4, 0}) || d.Tile().Pos != (Pos{0, 0}) {
+		t.Fatalf("swap: got %v and %v", src.Tile().Pos, d.Tile().Pos)
+	}
+	if b.TileAt[Pos{4, 0}].Occupier != src || b.TileAt[Pos{0, 0}].Occupier != d {
+		t.Fatalf("swap: occupiers are not swapped")
+	}
+	b.TileAt[Pos{0, 0}].Base = &BaseTile{Name: "lake", Cost: map[string]int{Swim: 1}}
+	d.Movement = Swim
+	s.Swap(src, src, d)
+	if src.Tile().Pos != (Pos{4, 0}) || d.Tile().Pos != (Pos{0, 0}) || b.TileAt[Pos{0, 0}].Occupier != d {
+		t.Fatalf("swap a walker onto a lake: want no swap, got %v and %v", d.Tile().Pos, src.Tile().Pos)
+	}
+}
@@ -16,3 +16,23 @@ var Around2Area = tiled.CreateArea([]tiled.Pos{
 	{0, 2}, {1, 2}, {2, 2}, {2, 1}, {2, 0}, {2, -1}, {2, -2}, {1, -2},
 	{0, -2}, {-1, -2}, {-2, -2}, {-2, -1}, {-2, 0}, {-2, 1}, {-2, 2}, {-1, 2},
 })
+
+// StraightArea returns positions from min to max steps straight from the origin, through each way of the board.
+// A board without Offset is regarded as a quad board.
+func StraightArea(b *tiled.Board, min, max int) tiled.Area {
+	var offsets []tiled.Pos
+	if b != nil && b.Offset != nil {
+		for _, w := range b.Ways {
+			offsets = append(offsets, b.Offset(w))
+		}
+	} else {
+		offsets = AxisArea.Poses()
+	}
+	poses := make([]tiled.Pos, 0)
+	for _, o := range offsets {
+		for n := min; n <= max; n++ {
+			poses = append(poses, tiled.Pos{o[0] * n, o[1] * n})
+		}
+	}
+	return tiled.CreateArea(poses)
+}
@@ -5,92 +5,150 @@ import (
 	"github.com/kybin/tiled/game/example"
 )
 
+// targets returns characters on the area, whose parties are hostile to the caster's.
+func targets(caster *tiled.Character, area tiled.Area) []*tiled.Character {
+	board := caster.Tile().Board
+	chars := make([]*tiled.Character, 0)
+	for _, p := range area.Poses() {
+		t := board.TileAt[p]
+		if t == nil || t.Occupier == nil {
+			continue
+		}
+		ch := t.Occupier
+		if !caster.Party.IsHostile(ch.Party) {
+			continue
+		}
+		chars = append(chars, ch)
+	}
+	return chars
+}
+
+// damage is an effect of an event reducing HP of the character by its value.
+// The damage is modified by the side the source attacks from.
+func damage(ev *tiled.CharacterEvent) {
+	dmg := ev.Value
+	if ev.Stage != nil {
+		dmg = ev.Stage.DamageFrom(ev.Source, ev.Character, dmg)
+	}
+	ev.Character.HP -= dmg
+}
+
+// attack creates events of the caster attacking the targets with the damage.
+func attack(caster *tiled.Character, targets []*tiled.Character, dmg func(ch *tiled.Character) int) []tiled.CharacterEvent {
+	events := make([]tiled.CharacterEvent, 0, len(targets)+1)
+	events = append(events, tiled.CharacterEvent{
+		Character: caster,
+		On:        "attack",
+	})
+	for _, ch := range targets {
+		events = append(events, tiled.CharacterEvent{
+			Character: ch,
+			Source:    caster,
+			On:        "attacked",
+			Value:     dmg(ch),
+			Effect:    damage,
+		})
+	}
+	return events
+}
+
+// boardOf returns the board the caster is on, or nil if it is not on a board.
+func boardOf(caster *tiled.Character) *tiled.Board {
+	if t := caster.Tile(); t != nil {
+		return t.Board
+	}
+	return nil
+}
+
+func init() {
+	tiled.RegisterClass("swordman", NewSwordman)
+	tiled.RegisterClass("spearman", NewSpearman)
+}
+
 type Knockback struct {
 	Caster *tiled.Character
 }
 
-func (a *Knockback) Origin(cursor *tiled.Tile) tiled.Tile {
+func (a *Knockback) Origin() *tiled.Tile {
 	return a.Caster.Tile()
 }
 
 func (a *Knockback) SelectableArea() tiled.Area {
-	return example.AxisArea
+	return example.StraightArea(boardOf(a.Caster), 1, 1)
 }
 
 func (a *Knockback) CastArea(sel tiled.Pos) tiled.Area {
 	return tiled.CreateArea([]tiled.Pos{sel})
 }
 
-func (a *Knockback) Cast(tiles []*tiled.Tile) {
-	for _, t := range tiles {
-		ch := t.Occupier
-		if ch.Party.IsAlly(a.Caster.Party) {
-			continue
-		}
-		if ch.Tile()[0] < a.Caster.Tile()[0] {
-			ch.Pos[0] = ch.Pos[0] - 1
-		}
-		if ch.Tile()[0] > a.Caster.Tile()[0] {
-			ch.Pos[0] = ch.Pos[0] + 1
-		}
-		if ch.Tile()[1] < a.Caster.Tile()[1] {
-			ch.Pos[1] = ch.Pos[1] - 1
-		}
-		if ch.Tile()[1] > a.Caster.Tile()[1] {
-			ch.Pos[1] = ch.Pos[1] + 1
-		}
-		ch.HP -= a.Caster.HP * a.Caster.AttackPower / 3
+func (a *Knockback) Cast(sel tiled.Pos) []tiled.CharacterEvent {
+	chars := targets(a.Caster, a.CastArea(sel))
+	events := attack(a.Caster, chars, func(ch *tiled.Character) int {
+		return a.Caster.Stats().AttackPower / 3
+	})
+	from := a.Caster.Tile()
+	for _, ch := range chars {
+		dir := from.Board.DirectionToward(from.Pos, ch.Tile().Pos)
+		events = append(events, tiled.CharacterEvent{
+			Character: ch,
+			Source:    a.Caster,
+			On:        "knockback",
+			Value:     1,
+			Effect: func(ev *tiled.CharacterEvent) {
+				ev.Stage.Push(ev.Source, ev.Character, dir, ev.Value)
+			},
+		})
 	}
+	return events
 }
 
 func NewSwordman(ch *tiled.Character) *tiled.Class {
-	cls := &tiled.Class{}
-	cls.Skill["attack"] = tiled.Skill(&SwordAttack{Caster: ch})
-	cls.Skill["knockback"] = tiled.Skill(&Knockback{Caster: ch})
+	cls := &tiled.Class{Name: "swordman", Skills: make(map[string]tiled.Skill)}
+	cls.Skills["attack"] = tiled.Skill(&SwordAttack{Caster: ch})
+	cls.Skills["knockback"] = tiled.Skill(&Knockback{Caster: ch})
+	return cls
 }
 
 type SwordAttack struct {
 	Caster *tiled.Character
 }
 
-func (a *SwordAttack) Origin(cursor *tiled.Tile) tiled.Tile {
+func (a *SwordAttack) Origin() *tiled.Tile {
 	return a.Caster.Tile()
 }
 
 func (a *SwordAttack) SelectableArea() tiled.Area {
-	return example.AxisArea
+	return example.StraightArea(boardOf(a.Caster), 1, 1)
 }
 
 func (a *SwordAttack) CastArea(sel tiled.Pos) tiled.Area {
 	return tiled.CreateArea([]tiled.Pos{sel})
 }
 
-func (a *SwordAttack) Cast(tiles []*tiled.Tile) {
-	for _, t := range tiles {
-		ch := t.Occupier
-		if ch.Party.IsAlly(a.Caster.Party) {
-			continue
-		}
-		ch.HP -= a.Caster.HP * a.Caster.AttackPower
-	}
+func (a *SwordAttack) Cast(sel tiled.Pos) []tiled.CharacterEvent {
+	chars := targets(a.Caster, a.CastArea(sel))
+	return attack(a.Caster, chars, func(ch *tiled.Character) int {
+		return a.Caster.Stats().AttackPower
+	})
 }
 
 func NewSpearman(ch *tiled.Character) *tiled.Class {
-	cls := &tiled.Class{}
-	cls.Skill["attack"] = tiled.Skill(&SpearAttack{Caster: ch})
-	cls.Skill["knockback"] = tiled.Skill(&Knockback{Caster: ch})
+	cls := &tiled.Class{Name: "spearman", Skills: make(map[string]tiled.Skill)}
+	cls.Skills["attack"] = tiled.Skill(&SpearAttack{Caster: ch})
+	cls.Skills["knockback"] = tiled.Skill(&Knockback{Caster: ch})
+	return cls
 }
 
 type SpearAttack struct {
 	Caster *tiled.Character
 }
 
-func (a *SpearAttack) Origin(cursor *tiled.Tile) tiled.Tile {
+func (a *SpearAttack) Origin() *tiled.Tile {
 	return a.Caster.Tile()
 }
 
 func (a *SpearAttack) SelectableArea() tiled.Area {
-	return example.AxisArea.Add(example.AxisArea2)
+	return example.StraightArea(boardOf(a.Caster), 1, 2)
 }
 
 func (a *SpearAttack) CastArea(sel tiled.Pos) tiled.Area {
@@ -98,47 +156,13 @@ func (a *SpearAttack) CastArea(sel tiled.Pos) tiled.Area {
 }
 
 func (a *SpearAttack) Cast(sel tiled.Pos) []tiled.CharacterEvent {
-	area := tiled.CreateArea([]tiled.Pos{sel})
-	tiles := make([]*tiled.Tile, 0, len(area))
-	for _, a := range area {
-		t := tiled.Board.Tile(a)
-		tiles = append(tiles, t)
-	}
-	events := []make(tiled.CharacterEvent, 0)
-	skillFacing := sel.Facing()
-	if a.Caster.Facing != skillFacing {
-		ev := tiled.CharacterEvent{
-			Character: a.Caster,
-			On:        "facing",
-			Effect: func() {
-				a.Caster.Facing = skillFacing
-			},
+	chars := targets(a.Caster, a.CastArea(sel))
+	at := a.Caster.Tile()
+	return attack(a.Caster, chars, func(ch *tiled.Character) int {
+		// spear is not good at close range.
+		if at.Board.Distance(at.Pos, ch.Tile().Pos) <= 1 {
+			return a.Caster.Stats().AttackPower / 2
 		}
-		events = append(events, ev)
-	}
-	ev := tiled.CharacterEvent{
-		Character: a.Caster,
-		On:        "attack",
-	}
-	events = append(events, ev)
-	for _, t := range tiles {
-		ch := t.Occupier
-		if ch.Party.IsAlly(a.Caster.Party) {
-			continue
-		}
-		k := 0.5
-		if tile.Distance(ch.Tile(), a.Caster.Tile()) > 1 {
-			k := 1
-		}
-		effect := func() {
-			ch.HP -= a.Caster.HP * a.Caster.AttackPower * k
-		}
-		ev := tiled.CharacterEvent{
-			Character: ch,
-			On:        "attacked",
-			Effect:    effect,
-		}
-		events = append(events, ev)
-	}
-	return events
+		return a.Caster.Stats().AttackPower
+	})
 }
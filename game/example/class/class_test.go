@@ -0,0 +1,94 @@
+package class
+
+import (
+	"testing"
+
+	"github.com/kybin/tiled"
+	"github.com/kybin/tiled/board/hex"
+	"github.com/kybin/tiled/board/quad"
+)
+
+func setup(t *testing.T) (*tiled.Stage, *tiled.Character, *tiled.Character) {
+	board := quad.NewBoard(5, 1)
+	a := &tiled.Party{}
+	b := &tiled.Party{}
+	ca := &tiled.Character{Party: a, HP: 10, AttackPower: 6}
+	cb := &tiled.Character{Party: b, HP: 10}
+	a.Characters = []*tiled.Character{ca}
+	b.Characters = []*tiled.Character{cb}
+	ca.Place(board.TileAt[tiled.Pos{0, 0}])
+	cb.Place(board.TileAt[tiled.Pos{2, 0}])
+	s := &tiled.Stage{Board: board, Parties: []*tiled.Party{a, b}}
+	return s, ca, cb
+}
+
+func TestSpearAttack(t *testing.T) {
+	s, ca, cb := setup(t)
+	sk := NewSpearman(ca).Skills["attack"]
+	if !s.Cast(sk, tiled.Pos{2, 0}) {
+		t.Fatalf("cast: want true, got false")
+	}
+	if cb.HP != 4 {
+		t.Fatalf("hp: want 4, got %v", cb.HP)
+	}
+	if s.Cast(sk, tiled.Pos{3, 0}) {
+		t.Fatalf("cast out of selectable area: want false, got true")
+	}
+	cb.Place(s.Board.TileAt[tiled.Pos{1, 0}])
+	s.Cast(sk, tiled.Pos{1, 0})
+	if cb.HP != 1 {
+		t.Fatalf("hp after close range attack: want 1, got %v", cb.HP)
+	}
+}
+
+func TestKnockback(t *testing.T) {
+	s, ca, cb := setup(t)
+	sk := NewSwordman(ca).Skills["knockback"]
+	cb.Place(s.Board.TileAt[tiled.Pos{1, 0}])
+	if !s.Cast(sk, tiled.Pos{1, 0}) {
+		t.Fatalf("cast: want true, got false")
+	}
+	if cb.HP != 8 {
+		t.Fatalf("hp: want 8, got %v", cb.HP)
+	}
+	if cb.Tile().Pos != (tiled.Pos{2, 0}) {
+		t.Fatalf("knockback: want %v, got %v", tiled.Pos{2, 0}, cb.Tile().Pos)
+	}
+	s.Undo()
+	if cb.HP != 10 || cb.Tile().Pos != (tiled.Pos{1, 0}) {
+		t.Fatalf("knockback is not undone")
+	}
+}
+
+func TestKnockbackCollision(t *testing.T) {
+	s, ca, cb := setup(t)
+	s.CollisionDamage = 3
+	sk := NewSwordman(ca).Skills["knockback"]
+	ca.Place(s.Board.TileAt[tiled.Pos{3, 0}])
+	cb.Place(s.Board.TileAt[tiled.Pos{4, 0}])
+	s.Cast(sk, tiled.Pos{4, 0})
+	if cb.HP != 5 || cb.Tile().Pos != (tiled.Pos{4, 0}) {
+		t.Fatalf("knockback to the board edge: want hp 5 at %v, got %v at %v", tiled.Pos{4, 0}, cb.HP, cb.Tile().Pos)
+	}
+}
+
+func TestSpearAttackHex(t *testing.T) {
+	board := hex.NewBoard(3, 5)
+	a := &tiled.Party{}
+	b := &tiled.Party{}
+	ca := &tiled.Character{Party: a, HP: 10, AttackPower: 6}
+	cb := &tiled.Character{Party: b, HP: 10}
+	a.Characters = []*tiled.Character{ca}
+	b.Characters = []*tiled.Character{cb}
+	// two steps to south east.
+	ca.Place(board.TileAt[tiled.Pos{0, 0}])
+	cb.Place(board.TileAt[tiled.Pos{2, 2}])
+	s := &tiled.Stage{Board: board, Parties: []*tiled.Party{a, b}}
+	sk := NewSpearman(ca).Skills["attack"]
+	if !s.Cast(sk, tiled.Pos{2, 2}) || cb.HP != 4 {
+		t.Fatalf("cast on hex board: want hp 4, got %v", cb.HP)
+	}
+	if s.Cast(sk, tiled.Pos{1, 0}) {
+		t.Fatalf("cast to a position not on hex board: want false, got true")
+	}
+}
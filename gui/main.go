@@ -11,6 +11,9 @@ import (
 	"sync"
 
 	"github.com/BurntSushi/toml"
+	"github.com/kybin/tiled"
+	"github.com/kybin/tiled/board"
+	"github.com/kybin/tiled/board/quad"
 	"golang.org/x/exp/shiny/driver"
 	"golang.org/x/exp/shiny/screen"
 	"golang.org/x/image/draw"
@@ -49,6 +52,9 @@ var (
 	cursorPoints = []image.Point{}
 )
 
+// topology converts between tiles and pixels of the stage.
+var topology board.Topology = quad.Topology{}
+
 type TextureLayer []TexPos
 
 type Stage struct {
@@ -60,6 +66,23 @@ type Stage struct {
 	TexAt     map[TexPos]screen.Texture
 }
 
+// TileAt returns position of the tile at the pixel. It could be out of the stage.
+func (s *Stage) TileAt(p image.Point) image.Point {
+	pos := topology.FromPixel(float64(p.X), float64(p.Y), float64(s.TileSize.X), float64(s.TileSize.Y))
+	return image.Pt(pos[0], pos[1])
+}
+
+// TileMin returns top left pixel of the tile.
+func (s *Stage) TileMin(tp image.Point) image.Point {
+	x, y := topology.ToPixel(tiled.Pos{tp.X, tp.Y}, float64(s.TileSize.X), float64(s.TileSize.Y))
+	return image.Pt(int(x)-s.TileSize.X/2, int(y)-s.TileSize.Y/2)
+}
+
+// Contains reports whether the tile is in the stage.
+func (s *Stage) Contains(tp image.Point) bool {
+	return tp.In(image.Rectangle{Max: s.Size})
+}
+
 func (s *Stage) TileTexs(p image.Point) []screen.Texture {
 	idx := p.Y*s.Size.X + p.X
 	texs := make([]screen.Texture, 0, len(s.TexLayers))
@@ -295,11 +318,10 @@ func main() {
 			case mouse.Event:
 				p := image.Point{X: int(e.X), Y: int(e.Y)}
 				topLeft = image.Pt(offset.X-winSize.X/2+stg.TileSize.X/2*stg.Size.X, offset.Y-winSize.Y/2+stg.TileSize.Y/2*stg.Size.Y)
-				x := (p.X + topLeft.X) / stg.TileSize.X
-				y := (p.Y + topLeft.Y) / stg.TileSize.Y
-				hx := x
-				hy := y
-				if hx < 0 || hx >= stg.Size.X || hy < 0 || hy >= stg.Size.Y {
+				tp := stg.TileAt(p.Add(topLeft))
+				hx := tp.X
+				hy := tp.Y
+				if !stg.Contains(tp) {
 					hx = -1
 					hy = -1
 				}
@@ -312,13 +334,11 @@ func main() {
 					}
 				}
 				if e.Button == mouse.ButtonLeft && e.Direction == mouse.DirRelease {
-					x := (p.X + topLeft.X) / stg.TileSize.X
-					y := (p.Y + topLeft.Y) / stg.TileSize.Y
-					if x < 0 || x >= stg.Size.X || y < 0 || y >= stg.Size.Y {
+					if !stg.Contains(tp) {
 						break
 					}
-					cursorPos[0] = x
-					cursorPos[1] = y
+					cursorPos[0] = tp.X
+					cursorPos[1] = tp.Y
 					if !paintPending {
 						paintPending = true
 						w.Send(paint.Event{})
@@ -350,17 +370,25 @@ func main() {
 				var wg sync.WaitGroup
 				drawBg(w, s, winSize)
 				topLeft = image.Pt(offset.X-winSize.X/2+stg.TileSize.X/2*stg.Size.X, offset.Y-winSize.Y/2+stg.TileSize.Y/2*stg.Size.Y)
-				for y := -(topLeft.Y & 0x7f); y < winSize.Y; y += stg.TileSize.Y {
-					for x := -(topLeft.X & 0x7f); x < winSize.X; x += stg.TileSize.X {
+				win := image.Rectangle{Max: winSize}
+				for y := 0; y < stg.Size.Y; y++ {
+					for x := 0; x < stg.Size.X; x++ {
+						tp := image.Pt(x, y)
+						at := stg.TileMin(tp).Sub(topLeft)
+						if !win.Overlaps(image.Rectangle{Min: at, Max: at.Add(stg.TileSize)}) {
+							continue
+						}
 						wg.Add(1)
-						go drawTile(&wg, w, stg, topLeft, x, y)
+						go drawTile(&wg, w, stg, tp, at)
 					}
 				}
 				wg.Wait()
 				if hoverPos[0] != -1 {
-					drawHover(w, stg, topLeft, -topLeft.X+hoverPos[0]*stg.TileSize.X, -topLeft.Y+hoverPos[1]*stg.TileSize.Y)
+					at := stg.TileMin(image.Pt(hoverPos[0], hoverPos[1])).Sub(topLeft)
+					drawHover(w, stg, at.X, at.Y)
 				}
-				drawCursor(w, stg, topLeft, -topLeft.X+cursorPos[0]*stg.TileSize.X, -topLeft.Y+cursorPos[1]*stg.TileSize.Y)
+				at := stg.TileMin(image.Pt(cursorPos[0], cursorPos[1])).Sub(topLeft)
+				drawCursor(w, stg, at.X, at.Y)
 				w.Publish()
 				paintPending = false
 
@@ -393,22 +421,16 @@ func drawBg(w screen.Window, s screen.Screen, winSize image.Point) {
 	w.Copy(image.Point{}, tex, tex.Bounds(), screen.Src, nil)
 }
 
-func drawTile(wg *sync.WaitGroup, w screen.Window, stg *Stage, topLeft image.Point, x, y int) {
+// drawTile draws textures of the tile at the point of the window.
+func drawTile(wg *sync.WaitGroup, w screen.Window, stg *Stage, tp, at image.Point) {
 	defer wg.Done()
-	tp := image.Point{
-		(x + topLeft.X) / stg.TileSize.X,
-		(y + topLeft.Y) / stg.TileSize.Y,
-	}
-	if tp.X < 0 || tp.X >= stg.Size.X || tp.Y < 0 || tp.Y >= stg.Size.Y {
-		return
-	}
 	texs := stg.TileTexs(tp)
 	for _, tex := range texs {
-		w.Copy(image.Point{x, y}, tex, tex.Bounds(), screen.Over, nil)
+		w.Copy(at, tex, tex.Bounds(), screen.Over, nil)
 	}
 }
 
-func drawCursor(w screen.Window, stg *Stage, topLeft image.Point, x, y int) {
+func drawCursor(w screen.Window, stg *Stage, x, y int) {
 	img := image.NewRGBA(image.Rect(0, 0, stg.TileSize.X, stg.TileSize.Y))
 	for _, p := range cursorPoints {
 		img.SetRGBA(p.X, p.Y, cursorColor)
@@ -422,7 +444,7 @@ func drawCursor(w screen.Window, stg *Stage, topLeft image.Point, x, y int) {
 	tex.Release()
 }
 
-func drawHover(w screen.Window, stg *Stage, topLeft image.Point, x, y int) {
+func drawHover(w screen.Window, stg *Stage, x, y int) {
 	img := image.NewRGBA(image.Rect(0, 0, stg.TileSize.X, stg.TileSize.Y))
 	for _, p := range cursorPoints {
 		img.SetRGBA(p.X, p.Y, hoverColor)
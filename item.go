@@ -0,0 +1,132 @@
+package tiled
+
+import (
+	"fmt"
+)
+
+// Item is an item a character carries or equips.
+type Item struct {
+	Name string
+	// Type is one of Game.ItemTypes. eg. "sword", "armor"
+	Type string
+	// Part is one of Game.EquipmentParts the item could be equipped to.
+	// The item cannot be equipped when it is empty.
+	Part string
+	// Modifier changes stats of the character equipping the item.
+	Modifier Stats
+}
+
+// Consumable is an item used up by characters. Same consumables are counted together.
+type Consumable struct {
+	Name string
+	// Count is how many of the consumable the character has.
+	Count int
+	// Cost is points spent to use the consumable.
+	Cost int
+	// Value is a value the Effect will use. eg. amount of healing
+	Value int
+	// Effect applies the consumable to the character used it.
+	// Effects should be registered with RegisterConsumableEffect to be saved.
+	Effect func(ev *CharacterEvent) `json:"-"`
+}
+
+// Equip equips the item on the character, at the item's part.
+// The item should be one of the game's item types, and its part should be one of the game's equipment parts.
+// The item should be one of the character's items, or already equipped by it.
+// An item already equipped at the part goes back to the character's items.
+func (g *Game) Equip(c *Character, it *Item) error {
+	if len(g.ItemTypes) != 0 && !contains(g.ItemTypes, it.Type) {
+		return fmt.Errorf("unknown item type: %v", it.Type)
+	}
+	if !contains(g.EquipmentParts, it.Part) {
+		return fmt.Errorf("%v cannot be equipped to %q", it.Name, it.Part)
+	}
+	if c.Equipments[it.Part] == it {
+		return nil
+	}
+	owned := false
+	for i, item := range c.Items {
+		if item == it {
+			c.Items = append(c.Items[:i:i], c.Items[i+1:]...)
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		return fmt.Errorf("%v is not an item of the character", it.Name)
+	}
+	c.Unequip(it.Part)
+	if c.Equipments == nil {
+		c.Equipments = make(map[string]*Item)
+	}
+	c.Equipments[it.Part] = it
+	return nil
+}
+
+// Unequip takes off the item at the part back to the character's items.
+// It returns the item, or nil if nothing is equipped at the part.
+func (c *Character) Unequip(part string) *Item {
+	it := c.Equipments[part]
+	if it == nil {
+		return nil
+	}
+	delete(c.Equipments, part)
+	c.Items = append(c.Items, it)
+	return it
+}
+
+// Consumable returns the character's consumable having the name, or nil if it doesn't have one.
+func (c *Character) Consumable(name string) *Consumable {
+	for _, cs := range c.Consumables {
+		if cs.Name == name && cs.Count > 0 {
+			return cs
+		}
+	}
+	return nil
+}
+
+// Use uses the consumable, which spends points of the character.
+// It reports whether the character could use it. It cannot use a consumable it doesn't have.
+// The effect of the consumable is returned as an event, so it could be resolved by a stage.
+func (c *Character) Use(cs *Consumable) (CharacterEvent, bool) {
+	if cs.Count <= 0 || c.Dead() || !c.has(cs) {
+		return CharacterEvent{}, false
+	}
+	if c.RemainingPoints-c.SpentPoints < cs.Cost {
+		return CharacterEvent{}, false
+	}
+	c.SpentPoints += cs.Cost
+	cs.Count--
+	ev := CharacterEvent{
+		Character: c,
+		Source:    c,
+		On:        "use",
+		Value:     cs.Value,
+		Effect:    cs.Effect,
+	}
+	return ev, true
+}
+
+// has reports whether the consumable is one of the character's.
+func (c *Character) has(cs *Consumable) bool {
+	for _, v := range c.Consumables {
+		if v == cs {
+			return true
+		}
+	}
+	return false
+}
+
+// Heal is an effect of a consumable, healing the character by its value up to its MaxHP.
+func Heal(ev *CharacterEvent) {
+	ev.Character.heal(ev.Value)
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func TestEquip(t *testing.T) {
+	g := &Game{EquipmentParts: []string{"hand", "body"}, ItemTypes: []string{"sword", "armor", "ring"}}
+	c := &Character{AttackPower: 3, MaxPoints: 4}
+	sword := &Item{Name: "sword", Type: "sword", Part: "hand", Modifier: Stats{AttackPower: 2}}
+	axe := &Item{Name: "axe", Type: "sword", Part: "hand", Modifier: Stats{AttackPower: 4, MaxPoints: -1}}
+	ring := &Item{Name: "ring", Type: "ring", Part: "finger"}
+	c.Items = []*Item{sword, axe, ring}
+	if err := g.Equip(c, ring); err == nil {
+		t.Fatalf("should not equip to unknown part")
+	}
+	if err := g.Equip(c, &Item{Name: "wand", Type: "wand", Part: "hand"}); err == nil {
+		t.Fatalf("should not equip unknown item type")
+	}
+	if err := g.Equip(c, sword); err != nil {
+		t.Fatal(err)
+	}
+	if st := c.Stats(); st.AttackPower != 5 || len(c.Items) != 2 {
+		t.Fatalf("equip sword: want attack power 5 and 2 items, got %v and %v items", st.AttackPower, len(c.Items))
+	}
+	if err := g.Equip(c, axe); err != nil {
+		t.Fatal(err)
+	}
+	if st := c.Stats(); st != (Stats{AttackPower: 7, MaxPoints: 3}) {
+		t.Fatalf("equip axe: want {7 3}, got %v", st)
+	}
+	if len(c.Items) != 2 || c.Items[1] != sword {
+		t.Fatalf("sword should be back to items")
+	}
+	if err := g.Equip(c, axe); err != nil || c.Equipments["hand"] != axe {
+		t.Fatalf("equip the equipped axe again: want no change, got %v", err)
+	}
+	if err := g.Equip(c, &Item{Name: "mace", Type: "sword", Part: "hand"}); err == nil || c.Equipments["hand"] != axe {
+		t.Fatalf("should not equip an item the character doesn't have")
+	}
+	if c.Unequip("hand") != axe || c.Stats().AttackPower != 3 || len(c.Items) != 3 {
+		t.Fatalf("unequip axe failed")
+	}
+}
+
+func TestUse(t *testing.T) {
+	a := newTestParty(5)
+	c := a.Characters[0]
+	c.MaxHP = 8
+	c.RemainingPoints = 3
+	potion := &Consumable{Name: "potion", Count: 2, Cost: 2, Value: 4, Effect: Heal}
+	c.Consumables = []*Consumable{potion}
+	s := &Stage{Parties: []*Party{a}}
+	if s.Use(c, &Consumable{Name: "potion", Count: 1, Value: 4, Effect: Heal}) || c.HP != 5 {
+		t.Fatalf("should not use a consumable the character doesn't have")
+	}
+	if !s.Use(c, potion) {
+		t.Fatalf("should use potion")
+	}
+	if c.HP != 8 || potion.Count != 1 || c.SpentPoints != 2 {
+		t.Fatalf("use: want hp 8, count 1, spent 2, got %v, %v, %v", c.HP, potion.Count, c.SpentPoints)
+	}
+	if s.Use(c, potion) {
+		t.Fatalf("should not use potion without enough points")
+	}
+	s.Undo()
+	if c.HP != 5 || potion.Count != 2 || c.SpentPoints != 0 {
+		t.Fatalf("undo: want hp 5, count 2, spent 0, got %v, %v, %v", c.HP, potion.Count, c.SpentPoints)
+	}
+	potion.Cost = 0
+	s.Use(c, potion)
+	s.Use(c, potion)
+	if c.Consumable("potion") != nil || s.Use(c, potion) {
+		t.Fatalf("potion should be used up")
+	}
+}
@@ -18,71 +18,99 @@ type Game struct {
 	World          *World
 	EquipmentParts []string
 	ItemTypes      []string
+	// Stage is the stage in progress. It is nil out of battles.
+	Stage *Stage
 }
 
-func (g *Game) Save() error {
-	return nil
+// Player is a person playing the game.
+type Player struct {
+	// Field is the field where the player is.
+	Field *Field
 }
 
-func (g *Game) Load() error {
-	return nil
-}
-
-type Field struct{}
-
 type Party struct {
 	World      *World
 	Characters []*Character
 	Strategy   *Strategy
+	// NPC party is controlled by AI instead of a player.
+	NPC bool
+	// Relations are how the party regards other parties, see Relation.
+	Relations map[*Party]Relation
 }
 
-func (p *Party) IsAlly(q *Party) bool {
-	allies := p.World.Allies[p]
-	for _, a := range allies {
-		if q == a {
-			return true
+// Eliminated reports whether every character of the party is dead.
+func (p *Party) Eliminated() bool {
+	for _, c := range p.Characters {
+		if !c.Dead() {
+			return false
 		}
 	}
-	return false
+	return true
 }
 
-func (p *Party) AutoAction() {
+// AutoAction lets characters of the party act by their strategy in the stage.
+// Only the active character acts, when the stage uses a timeline.
+func (p *Party) AutoAction(s *Stage) {
 	stg := p.Strategy
 	if stg == nil {
-		stg = p.World.DefaultStrategy
+		stg = s.DefaultStrategy
 	}
 	if stg == nil {
 		panic("default strategy should not be nil")
 	}
 	for _, np := range p.Characters {
-		stg.Action(np)
+		if s.ActiveCharacter != nil && np != s.ActiveCharacter {
+			continue
+		}
+		stg.Action(s, np)
 	}
 }
 
 type Action struct {
-	Name      string
-	Sequence  []image.Image
+	Name string
+	// Sequence is images shown evenly through Lifetime.
+	// It is used when Animation doesn't have frames.
+	Sequence []image.Image
+	// Lifetime is how long the action lasts.
+	// When it is zero, the action lasts as long as its animation,
+	// or until another action is pending if the animation loops.
 	Lifetime  time.Duration
 	Age       time.Duration
 	Animation Animation
+	// Events are called when frames having their names start.
+	Events map[string]func(c *Character)
+	// resolving are events a stage resolves when a frame named resolveOn starts.
+	// eg. "hit" frame of an attack action applies the damage. See AnimatedSkill.
+	resolveOn string
+	resolving []CharacterEvent
+	stage     *Stage
 }
 
+// Image returns the image of the action at its age.
 func (a *Action) Image() image.Image {
+	if len(a.Animation.Frames) != 0 {
+		return a.Animation.Frames[a.Animation.FrameAt(a.Age)].Image
+	}
 	n := len(a.Sequence)
-	i := n * a.Age / a.Lifetime
+	if n == 0 {
+		return nil
+	}
+	if a.Lifetime <= 0 {
+		return a.Sequence[0]
+	}
+	i := int(int64(n) * int64(a.Age) / int64(a.Lifetime))
+	if i >= n {
+		i = n - 1
+	}
 	return a.Sequence[i]
 }
 
-type State struct {
-	Name string
-}
-
 type Character struct {
 	Party           *Party
 	Class           *Class
 	RestAction      *Action
 	PendingActions  []*Action
-	States          map[State]bool
+	States          map[string]*State
 	Equipments      map[string]*Item
 	Items           []*Item
 	Consumables     []*Consumable
@@ -95,54 +123,168 @@ type Character struct {
 	AttackPower     int
 	Skills          map[string]Skill
 	HP              int
+	// MaxHP limits healing of the character. Zero means no limit.
+	MaxHP int
+	// Sight is how far the character can see.
+	// The character sees as far as line of sight goes when it is zero.
+	Sight int
+	// Movement is movement class of the character, which decides terrains it can enter
+	// and their costs. See BaseTile.Cost. It is Walk when empty.
+	Movement string
+	// MoveCost overrides cost of a Way for the character, when defined.
+	// eg. Who can swim makes lake tile costs less.
+	MoveCost func(w *Way) int
+	// Speed is how fast charge time of the character charges, see Timeline.
+	Speed int
+	// Facing is name of the way the character is facing. eg. "N"
+	// It changes when the character moves, attacks or casts a skill.
+	Facing string
 }
 
+// Tick passes time of the character by d.
+// Pending actions are played in order, triggering their frame events.
+// Time left after an action is finished goes to the next action.
+// The rest action is played while nothing is pending.
 func (c *Character) Tick(d time.Duration) {
-	if len(c.PendingActions) == 0 {
-		c.RestAction.Age = 0
-		c.PendingActions = append(c.PendingActions, c.RestAction)
-	}
-	a := c.PendingActions[0]
-	if a.Lifetime != 0 {
-		a.Age += d
-		if a.Age >= a.Lifetime {
-			// finish the action
+	c.AdvanceStates(PerTick)
+	c.play(d)
+}
+
+// play plays pending actions of the character by d, see Tick.
+func (c *Character) play(d time.Duration) {
+	for {
+		if len(c.PendingActions) == 0 {
+			if c.RestAction == nil {
+				return
+			}
+			c.RestAction.Age = 0
+			c.PendingActions = append(c.PendingActions, c.RestAction)
+		}
+		a := c.PendingActions[0]
+		if a.lifetime() == 0 && len(c.PendingActions) > 1 {
+			// endless action is interrupted by the next one.
+			a.resolve()
 			c.PendingActions = c.PendingActions[1:]
+			continue
+		}
+		left, finished := a.advance(c, d)
+		if !finished {
+			return
+		}
+		// finish the action
+		c.PendingActions = c.PendingActions[1:]
+		if left == 0 {
+			return
 		}
+		d = left
 	}
-
 }
 
 func (c *Character) Tile() *Tile {
-	if len(w.Moves) == 0 {
+	if len(c.Moves) == 0 {
 		return c.Origin
 	}
-	return w.Moves[len(w.Moves)-1].To
+	return c.Moves[len(c.Moves)-1].To
+}
+
+// Dead reports whether the character is dead.
+func (c *Character) Dead() bool {
+	return c.HP <= 0
+}
+
+// Place puts the character on the tile, regardless of ways between them.
+// It clears moves of the character, but not points spent by them.
+// It returns false when the tile is occupied by another character.
+func (c *Character) Place(t *Tile) bool {
+	if !c.canEnter(t) {
+		return false
+	}
+	if at := c.Tile(); at != nil && at.Occupier == c {
+		at.Occupier = nil
+	}
+	c.Origin = t
+	c.Moves = nil
+	t.Occupier = c
+	return true
+}
+
+// WayCost returns cost of the Way for the character.
+// It is Way.Cost scaled by terrain of the tile the way goes to, by the character's movement class.
+// It is at least 1, even if MoveCost says otherwise.
+func (c *Character) WayCost(w *Way) int {
+	cost := w.Cost
+	if w.To.Base != nil {
+		if m, ok := w.To.Base.CostFor(c.movement()); ok {
+			cost *= m
+		}
+	}
+	if c.MoveCost != nil {
+		cost = c.MoveCost(w)
+	}
+	if cost < 1 {
+		cost = 1
+	}
+	return cost
+}
+
+// canEnter checks whether the character can stand on the tile.
+func (c *Character) canEnter(t *Tile) bool {
+	return c.canStand(t) && (t.Occupier == nil || t.Occupier == c)
+}
+
+// canPass checks whether the character can move through the way by itself.
+func (c *Character) canPass(w *Way) bool {
+	if w.Ledge || !c.canEnter(w.To) {
+		return false
+	}
+	if len(w.Corners) == 0 || w.From.Board == nil {
+		return true
+	}
+	blocked := 0
+	for _, p := range w.Corners {
+		if t := w.From.Board.TileAt[p]; t == nil || !c.canEnter(t) {
+			blocked++
+		}
+	}
+	switch w.From.Board.CornerCut {
+	case CutOneCorner:
+		return blocked <= 1
+	case NoCornerCut:
+		return blocked == 0
+	}
+	return true
 }
 
 func (c *Character) Step(w Way) bool {
-	if w.From != c.Tile() {
+	if w.From != c.Tile() || !c.canPass(&w) {
 		return false
 	}
 	if w.To.Occupier != nil {
 		return false
 	}
-	if c.RemainingPoints-c.SpentPoints < w.Cost {
+	cost := c.WayCost(&w)
+	if c.RemainingPoints-c.SpentPoints < cost {
 		return false
 	}
 	w.From.Occupier = nil
 	c.Moves = append(c.Moves, w)
-	c.SpentPoints += w.Cost
+	c.SpentPoints += cost
+	c.Facing = w.Name
 	w.To.Occupier = c
 	return true
 }
 
+// MoveTo moves the character to the tile through the cheapest path.
+// It doesn't move at all when the character cannot afford the path.
 func (c *Character) MoveTo(t *Tile) bool {
-	path := findPath(c.Tile(), t)
+	path := findPath(c, c.Tile(), t)
 	if path == nil {
 		return false
 	}
-	for _, w := range path {
+	if c.RemainingPoints-c.SpentPoints < path.Cost {
+		return false
+	}
+	for _, w := range path.Ways {
 		ok := c.Step(w)
 		if !ok {
 			return false
@@ -151,7 +293,19 @@ func (c *Character) MoveTo(t *Tile) bool {
 	return true
 }
 
-func (c *Character) Attack(t *Tile) {
+// Attack attacks the character on the tile. It reports whether it attacked.
+// Use Stage.Attack for damage modified by the side it attacks from.
+func (c *Character) Attack(t *Tile) bool {
+	if !c.canAttack(t) {
+		return false
+	}
+	c.Face(t.Pos)
+	t.Occupier.HP -= c.Stats().AttackPower
+	return true
+}
+
+// canAttack reports whether the character can attack the character on the tile.
+func (c *Character) canAttack(t *Tile) bool {
 	attackable := false
 	for _, at := range c.AttackableTiles() {
 		if at == t {
@@ -159,21 +313,20 @@ func (c *Character) Attack(t *Tile) {
 		}
 	}
 	if !attackable {
-		return
-	}
-	if t.Occupier != nil {
-		return
-	}
-	if c.Party.IsAlly(t.Occupier.Party) {
-		return
+		return false
 	}
-	t.Occupier.HP -= c.AttackPower
-	if t.Occupier.HP <= 0 {
-		t.Occupier.State["dead"] = true
+	if t.Occupier == nil {
+		return false
 	}
+	return c.Party.IsHostile(t.Occupier.Party)
 }
 
 func (c *Character) AttackableTiles() []*Tile {
+	return c.attackableFrom(c.Tile())
+}
+
+// attackableFrom returns tiles the character could attack if it is on the tile.
+func (c *Character) attackableFrom(from *Tile) []*Tile {
 	// eg) c.AttackDir == [["N", "E"], ["E", "S"], ["S", "W"], ["W", "N"]] would represent
 	// the tiles marked with + for char @ in tiles generated by TileGenerator2D.
 	// + +
@@ -181,15 +334,23 @@ func (c *Character) AttackableTiles() []*Tile {
 	// + +
 	tiles := make([]*Tile, 0)
 	for _, dirs := range c.AttackDirs {
-		at := c.Tile()
+		at := from
 		for _, d := range dirs {
 			w := at.Way(d)
 			if w == nil {
-				continue
+				at = nil
+				break
 			}
 			at = w.To
 		}
-		tiles = append(tiles, w)
+		if at == nil {
+			continue
+		}
+		// cannot attack through walls.
+		if at.Board != nil && !at.Board.LineOfSight(from.Pos, at.Pos) {
+			continue
+		}
+		tiles = append(tiles, at)
 	}
 	return tiles
 }
@@ -207,34 +368,18 @@ func (c *Character) Commit() {
 func (c *Character) Done() {
 	c.Origin = c.Tile()
 	c.Moves = nil
-	c.RemainingPoints = c.MaxPoints
+	c.RemainingPoints = c.Stats().MaxPoints
 	c.SpentPoints = 0
 }
 
 type Class struct {
-	Skills []Skill
-}
-
-type Skill interface {
-	Origin() *Tile
-	SelectableArea() Area
-	CastArea() Area
-	Cast()
+	// Name is name of the class, see RegisterClass.
+	Name   string
+	Skills map[string]Skill
 }
 
 type Interactable struct {
 }
 
-func findPath(a, b *Tile) *Path {
-	// TODO
-}
-
-type Path struct {
-	Ways []Way
-	// Cost can be over or under to sum of costs of the Ways.
-	// Caused by advantages of the Character. eg. Who can swim makes lake tile costs less.
-	Cost int
-}
-
 func main() {
 }
@@ -0,0 +1,171 @@
+package tiled
+
+import (
+	"container/heap"
+)
+
+type Path struct {
+	Ways []Way
+	// Cost can be over or under to sum of costs of the Ways.
+	// Caused by advantages of the Character. eg. Who can swim makes lake tile costs less.
+	Cost int
+}
+
+// findPath finds the cheapest path of c from a to b with A* search.
+// Costs of ways are weighted by the character, see Character.WayCost.
+// Tiles occupied by other characters cannot be passed through.
+// It returns nil when b is not reachable from a.
+func findPath(c *Character, a, b *Tile) *Path {
+	if a == nil || b == nil {
+		return nil
+	}
+	if a == b {
+		return &Path{}
+	}
+	if !c.canEnter(b) {
+		return nil
+	}
+	estimate := func(t *Tile) int {
+		if b.Board == nil || b.Board.Distance == nil {
+			return 0
+		}
+		return b.Board.Distance(t.Pos, b.Pos)
+	}
+	s := newPathSearch(c, a)
+	s.run(b, -1, estimate)
+	return s.path(b)
+}
+
+// ReachableTiles returns area of tiles the character can move to with
+// remaining points of the turn, and the cheapest path to each of them.
+// It floods from the current tile, which is Origin unless the character moved.
+// The current tile is in the area as well, with an empty path.
+func (c *Character) ReachableTiles() (Area, map[Pos]*Path) {
+	return c.reachable(c.Tile(), c.RemainingPoints-c.SpentPoints)
+}
+
+// reachable returns area of tiles reachable from the tile with the points.
+func (c *Character) reachable(from *Tile, points int) (Area, map[Pos]*Path) {
+	s := newPathSearch(c, from)
+	s.run(nil, points, nil)
+	poses := make([]Pos, 0, len(s.cost))
+	paths := make(map[Pos]*Path, len(s.cost))
+	for t := range s.cost {
+		poses = append(poses, t.Pos)
+		paths[t.Pos] = s.path(t)
+	}
+	return CreateArea(poses), paths
+}
+
+// pathSearch explores tiles from a tile, cheaper ones first.
+type pathSearch struct {
+	c    *Character
+	from *Tile
+	cost map[*Tile]int
+	came map[*Tile]*Way
+}
+
+func newPathSearch(c *Character, from *Tile) *pathSearch {
+	return &pathSearch{
+		c:    c,
+		from: from,
+		cost: map[*Tile]int{from: 0},
+		came: make(map[*Tile]*Way),
+	}
+}
+
+// run explores tiles until it reaches goal.
+// When goal is nil, it explores every tile costs limit or less.
+// Negative limit means there is no limit.
+// Nil estimate makes the search Dijkstra's.
+func (s *pathSearch) run(goal *Tile, limit int, estimate func(t *Tile) int) {
+	if estimate == nil {
+		estimate = func(t *Tile) int { return 0 }
+	}
+	q := &pathQueue{}
+	heap.Push(q, &pathNode{tile: s.from, est: estimate(s.from)})
+	seq := 0
+	for q.Len() != 0 {
+		n := heap.Pop(q).(*pathNode)
+		if n.cost > s.cost[n.tile] {
+			// cheaper route to the tile is found after this was queued.
+			continue
+		}
+		if n.tile == goal {
+			return
+		}
+		for _, w := range n.tile.Ways {
+			if !s.c.canPass(w) {
+				continue
+			}
+			wc := n.cost + s.c.WayCost(w)
+			if limit >= 0 && wc > limit {
+				continue
+			}
+			if old, ok := s.cost[w.To]; ok && old <= wc {
+				continue
+			}
+			s.cost[w.To] = wc
+			s.came[w.To] = w
+			seq++
+			heap.Push(q, &pathNode{tile: w.To, cost: wc, est: wc + estimate(w.To), seq: seq})
+		}
+	}
+}
+
+// path returns the cheapest path to t found so far, or nil if t isn't found.
+func (s *pathSearch) path(t *Tile) *Path {
+	if t == s.from {
+		return &Path{}
+	}
+	if s.came[t] == nil {
+		return nil
+	}
+	n := 0
+	for at := t; at != s.from; at = s.came[at].From {
+		n++
+	}
+	ways := make([]Way, n)
+	for at := t; at != s.from; at = s.came[at].From {
+		n--
+		ways[n] = *s.came[at]
+	}
+	return &Path{Ways: ways, Cost: s.cost[t]}
+}
+
+type pathNode struct {
+	tile *Tile
+	cost int
+	est  int
+	seq  int
+}
+
+// pathQueue is a priority queue of pathNode.
+// Nodes having lower estimation pop first. Tie breaks to the node having
+// higher cost, as it is closer to the destination, then to the older one.
+type pathQueue []*pathNode
+
+func (q pathQueue) Len() int { return len(q) }
+
+func (q pathQueue) Less(i, j int) bool {
+	if q[i].est != q[j].est {
+		return q[i].est < q[j].est
+	}
+	if q[i].cost != q[j].cost {
+		return q[i].cost > q[j].cost
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q pathQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *pathQueue) Push(x any) {
+	*q = append(*q, x.(*pathNode))
+}
+
+func (q *pathQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
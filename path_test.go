@@ -0,0 +1,187 @@
+package tiled
+
+import (
+	"testing"
+)
+
+// newTestBoard creates a 4-way board from rows of the map.
+// '#' is a tile that costs 3 to enter, and ' ' is a hole.
+func newTestBoard(rows []string) *Board {
+	b := &Board{
+		Ways:   []string{"N", "E", "S", "W"},
+		TileAt: make(map[Pos]*Tile),
+		Distance: func(a, b Pos) int {
+			dx, dy := a[0]-b[0], a[1]-b[1]
+			if dx < 0 {
+				dx = -dx
+			}
+			if dy < 0 {
+				dy = -dy
+			}
+			return dx + dy
+		},
+		Rotate: func(p Pos, n int) Pos {
+			for i := 0; i < ((n%4)+4)%4; i++ {
+				p = Pos{-p[1], p[0]}
+			}
+			return p
+		},
+	}
+	for y, row := range rows {
+		for x, r := range row {
+			if r == ' ' {
+				continue
+			}
+			b.TileAt[Pos{x, y}] = &Tile{Pos: Pos{x, y}, Board: b}
+		}
+	}
+	dirs := map[string]Pos{"N": {0, -1}, "E": {1, 0}, "S": {0, 1}, "W": {-1, 0}}
+	for pos, t := range b.TileAt {
+		for _, name := range b.Ways {
+			at := pos.Add(dirs[name])
+			to := b.TileAt[at]
+			if to == nil {
+				continue
+			}
+			cost := 1
+			if rows[at[1]][at[0]] == '#' {
+				cost = 3
+			}
+			t.Ways = append(t.Ways, &Way{Name: name, From: t, To: to, Cost: cost})
+		}
+	}
+	return b
+}
+
+func TestFindPath(t *testing.T) {
+	b := newTestBoard([]string{
+		".....",
+		".###.",
+		". ...",
+	})
+	c := &Character{Origin: b.TileAt[Pos{0, 1}], RemainingPoints: 10}
+	c.Origin.Occupier = c
+	cases := []struct {
+		to   Pos
+		cost int
+	}{
+		{to: Pos{0, 1}, cost: 0},
+		{to: Pos{0, 0}, cost: 1},
+		{to: Pos{4, 1}, cost: 6},
+		{to: Pos{2, 2}, cost: 7},
+	}
+	for _, cs := range cases {
+		p := findPath(c, c.Tile(), b.TileAt[cs.to])
+		if p == nil {
+			t.Fatalf("path to %v: want cost %v, got nil", cs.to, cs.cost)
+		}
+		if p.Cost != cs.cost {
+			t.Fatalf("path to %v: want cost %v, got %v", cs.to, cs.cost, p.Cost)
+		}
+		at := c.Tile()
+		sum := 0
+		for _, w := range p.Ways {
+			if w.From != at {
+				t.Fatalf("path to %v: disconnected at %v", cs.to, at.Pos)
+			}
+			at = w.To
+			sum += w.Cost
+		}
+		if at.Pos != cs.to {
+			t.Fatalf("path to %v: ends at %v", cs.to, at.Pos)
+		}
+		if sum != p.Cost {
+			t.Fatalf("path to %v: want sum of way costs %v, got %v", cs.to, p.Cost, sum)
+		}
+	}
+}
+
+func TestFindPathOccupied(t *testing.T) {
+	b := newTestBoard([]string{
+		"...",
+		"...",
+	})
+	c := &Character{Origin: b.TileAt[Pos{0, 0}], RemainingPoints: 10}
+	c.Origin.Occupier = c
+	b.TileAt[Pos{1, 0}].Occupier = &Character{}
+	p := findPath(c, c.Tile(), b.TileAt[Pos{2, 0}])
+	if p == nil || p.Cost != 4 {
+		t.Fatalf("path around occupier: want cost 4, got %v", p)
+	}
+	b.TileAt[Pos{1, 1}].Occupier = &Character{}
+	p = findPath(c, c.Tile(), b.TileAt[Pos{2, 0}])
+	if p != nil {
+		t.Fatalf("path through occupiers: want nil, got %v", p)
+	}
+	p = findPath(c, c.Tile(), b.TileAt[Pos{1, 1}])
+	if p != nil {
+		t.Fatalf("path to occupied tile: want nil, got %v", p)
+	}
+}
+
+func TestMoveTo(t *testing.T) {
+	b := newTestBoard([]string{
+		"..#..",
+	})
+	c := &Character{Origin: b.TileAt[Pos{0, 0}], RemainingPoints: 4}
+	c.Origin.Occupier = c
+	if c.MoveTo(b.TileAt[Pos{4, 0}]) {
+		t.Fatalf("move beyond points: want false, got true")
+	}
+	if c.Tile().Pos != (Pos{0, 0}) {
+		t.Fatalf("failed move should not move: want %v, got %v", Pos{0, 0}, c.Tile().Pos)
+	}
+	// swimmer pays less for the '#' tile.
+	c.MoveCost = func(w *Way) int {
+		if w.Cost == 3 {
+			return 1
+		}
+		return w.Cost
+	}
+	if !c.MoveTo(b.TileAt[Pos{4, 0}]) {
+		t.Fatalf("move of swimmer: want true, got false")
+	}
+	if c.SpentPoints != 4 {
+		t.Fatalf("spent points: want 4, got %v", c.SpentPoints)
+	}
+	if b.TileAt[Pos{0, 0}].Occupier != nil || b.TileAt[Pos{4, 0}].Occupier != c {
+		t.Fatalf("occupier is not moved")
+	}
+}
+
+func TestReachableTiles(t *testing.T) {
+	b := newTestBoard([]string{
+		"....",
+		".#..",
+		"....",
+	})
+	c := &Character{Origin: b.TileAt[Pos{0, 0}], RemainingPoints: 4}
+	c.Origin.Occupier = c
+	b.TileAt[Pos{2, 0}].Occupier = &Character{}
+	area, paths := c.ReachableTiles()
+	want := []Pos{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {2, 2}}
+	got := area.Poses()
+	if len(got) != len(want) {
+		t.Fatalf("reachable: want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("reachable: want %v, got %v", want, got)
+		}
+	}
+	costs := map[Pos]int{{0, 0}: 0, {1, 0}: 1, {1, 1}: 4, {2, 2}: 4}
+	for pos, cost := range costs {
+		p := paths[pos]
+		if p == nil || p.Cost != cost {
+			t.Fatalf("path to %v: want cost %v, got %v", pos, cost, p)
+		}
+		if len(p.Ways) != 0 && p.Ways[len(p.Ways)-1].To.Pos != pos {
+			t.Fatalf("path to %v: ends at %v", pos, p.Ways[len(p.Ways)-1].To.Pos)
+		}
+	}
+	c.Step(*b.TileAt[Pos{0, 0}].Way("S"))
+	area, _ = c.ReachableTiles()
+	if n := len(area.Poses()); n != 7 {
+		t.Fatalf("reachable after a step: want 7 tiles, got %v", n)
+	}
+}
@@ -0,0 +1,318 @@
+package tiled
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+)
+
+// ReplayVersion is the version of replay files written by this package.
+const ReplayVersion = 1
+
+// Replay is a record of inputs to a stage.
+// Playing it on a stage set up the same way reproduces the battle.
+type Replay struct {
+	Version int
+	Seed    int64
+	Inputs  []Input
+}
+
+// Input is an input to a stage.
+// Characters are indexed by their party's index in Stage.Parties
+// and their index in Party.Characters.
+type Input struct {
+	Op        string
+	Party     int `json:",omitempty"`
+	Character int `json:",omitempty"`
+	Pos       Pos
+	Way       string `json:",omitempty"`
+	Skill     string `json:",omitempty"`
+	Item      string `json:",omitempty"`
+	CT        int    `json:",omitempty"`
+	// Hash is hash of the stage after a turn over.
+	Hash uint64 `json:",omitempty"`
+}
+
+// ReadReplay reads a replay written by Recorder.
+func ReadReplay(r io.Reader) (*Replay, error) {
+	rp := &Replay{}
+	err := json.NewDecoder(r).Decode(rp)
+	if err != nil {
+		return nil, err
+	}
+	if rp.Version != ReplayVersion {
+		return nil, fmt.Errorf("unsupported replay version: %v", rp.Version)
+	}
+	return rp, nil
+}
+
+// Recorder records inputs to a stage as a replay, while passing them to the stage.
+type Recorder struct {
+	Stage  *Stage
+	Replay *Replay
+}
+
+// NewRecorder creates a new recorder for the stage.
+// The stage should not be started yet.
+func NewRecorder(s *Stage) *Recorder {
+	return &Recorder{
+		Stage:  s,
+		Replay: &Replay{Version: ReplayVersion, Seed: s.Seed},
+	}
+}
+
+// Write writes the replay.
+func (r *Recorder) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r.Replay)
+}
+
+func (r *Recorder) record(in Input) {
+	r.Replay.Inputs = append(r.Replay.Inputs, in)
+}
+
+func (r *Recorder) input(op string, c *Character) Input {
+	pi, ci := r.Stage.indexOf(c)
+	return Input{Op: op, Party: pi, Character: ci}
+}
+
+func (r *Recorder) Start() {
+	r.record(Input{Op: "start"})
+	r.Stage.Start()
+}
+
+func (r *Recorder) Step(c *Character, way string) bool {
+	in := r.input("step", c)
+	in.Way = way
+	r.record(in)
+	return r.Stage.apply(in)
+}
+
+func (r *Recorder) MoveTo(c *Character, t *Tile) bool {
+	in := r.input("move", c)
+	in.Pos = t.Pos
+	r.record(in)
+	return r.Stage.apply(in)
+}
+
+func (r *Recorder) Attack(c *Character, t *Tile) bool {
+	in := r.input("attack", c)
+	in.Pos = t.Pos
+	r.record(in)
+	return r.Stage.apply(in)
+}
+
+// Cast casts a skill of the character, by its name.
+func (r *Recorder) Cast(c *Character, skill string, sel Pos) bool {
+	in := r.input("cast", c)
+	in.Skill = skill
+	in.Pos = sel
+	r.record(in)
+	return r.Stage.apply(in)
+}
+
+// Use uses a consumable of the character, by its name.
+func (r *Recorder) Use(c *Character, item string) bool {
+	in := r.input("use", c)
+	in.Item = item
+	r.record(in)
+	return r.Stage.apply(in)
+}
+
+// Delay delays the character in the stage's timeline.
+func (r *Recorder) Delay(c *Character, ct int) bool {
+	in := r.input("delay", c)
+	in.CT = ct
+	r.record(in)
+	return r.Stage.apply(in)
+}
+
+func (r *Recorder) Done(c *Character) {
+	in := r.input("done", c)
+	r.record(in)
+	r.Stage.apply(in)
+}
+
+func (r *Recorder) AutoAction(p *Party) {
+	in := Input{Op: "auto", Party: -1}
+	for i, q := range r.Stage.Parties {
+		if q == p {
+			in.Party = i
+		}
+	}
+	r.record(in)
+	r.Stage.apply(in)
+}
+
+func (r *Recorder) Undo() bool {
+	r.record(Input{Op: "undo"})
+	return r.Stage.Undo()
+}
+
+func (r *Recorder) Redo() bool {
+	r.record(Input{Op: "redo"})
+	return r.Stage.Redo()
+}
+
+func (r *Recorder) Commit() {
+	r.record(Input{Op: "commit"})
+	r.Stage.Commit()
+}
+
+// TurnOver passes the turn, and records hash of the stage to check desync.
+func (r *Recorder) TurnOver() {
+	r.Stage.TurnOver()
+	r.record(Input{Op: "turnover", Hash: r.Stage.Hash()})
+}
+
+// Play plays the replay on the stage. The stage should be set up as same as
+// the recorded one, but not started yet.
+// It returns an error when the stage went different from the recorded one.
+func (rp *Replay) Play(s *Stage) error {
+	s.Seed = rp.Seed
+	for i, in := range rp.Inputs {
+		switch in.Op {
+		case "start":
+			s.Start()
+		case "undo":
+			s.Undo()
+		case "redo":
+			s.Redo()
+		case "commit":
+			s.Commit()
+		case "turnover":
+			s.TurnOver()
+			if h := s.Hash(); h != in.Hash {
+				return fmt.Errorf("desync at input %v, turn %v: want hash %x, got %x", i, s.CurrentTurn(), in.Hash, h)
+			}
+		default:
+			if in.Party < 0 || in.Party >= len(s.Parties) {
+				return fmt.Errorf("invalid party at input %v: %v", i, in.Party)
+			}
+			if in.Op != "auto" && (in.Character < 0 || in.Character >= len(s.Parties[in.Party].Characters)) {
+				return fmt.Errorf("invalid character at input %v: %v", i, in.Character)
+			}
+			s.apply(in)
+		}
+	}
+	return nil
+}
+
+// apply applies an input on a character or a party to the stage.
+func (s *Stage) apply(in Input) bool {
+	p := s.Parties[in.Party]
+	if in.Op == "auto" {
+		p.AutoAction(s)
+		return true
+	}
+	c := p.Characters[in.Character]
+	switch in.Op {
+	case "step":
+		w := c.Tile().Way(in.Way)
+		if w == nil {
+			return false
+		}
+		return s.Step(c, *w)
+	case "move":
+		t := s.Board.TileAt[in.Pos]
+		if t == nil {
+			return false
+		}
+		return s.MoveTo(c, t)
+	case "attack":
+		t := s.Board.TileAt[in.Pos]
+		if t == nil {
+			return false
+		}
+		return s.Attack(c, t)
+	case "cast":
+		sk := c.skills()[in.Skill]
+		if sk == nil {
+			return false
+		}
+		return s.Cast(sk, in.Pos)
+	case "use":
+		cs := c.Consumable(in.Item)
+		if cs == nil {
+			return false
+		}
+		return s.Use(c, cs)
+	case "delay":
+		return s.Delay(c, in.CT)
+	case "done":
+		c.Done()
+		return true
+	}
+	return false
+}
+
+// indexOf returns index of the character's party and the character's index in the party.
+// It returns -1 for both, if the character isn't in the stage.
+func (s *Stage) indexOf(c *Character) (int, int) {
+	for i, p := range s.Parties {
+		for j, ch := range p.Characters {
+			if ch == c {
+				return i, j
+			}
+		}
+	}
+	return -1, -1
+}
+
+// Hash returns a hash of the stage's state.
+// Stages in the same state have the same hash.
+func (s *Stage) Hash() uint64 {
+	h := fnv.New64a()
+	write := func(vs ...int) {
+		for _, v := range vs {
+			binary.Write(h, binary.LittleEndian, int64(v))
+		}
+	}
+	active := -1
+	for i, p := range s.Parties {
+		if p == s.ActiveParty {
+			active = i
+		}
+	}
+	write(s.turn, active)
+	// different numbers drawn would desync the stage sooner or later.
+	var draws int64
+	if s.src != nil {
+		draws = s.src.n
+	}
+	write(int(draws))
+	for _, p := range s.Parties {
+		for _, q := range s.Parties {
+			write(int(p.Relation(q)))
+		}
+	}
+	for _, p := range s.Parties {
+		for _, c := range p.Characters {
+			pos := Pos{-1, -1}
+			if t := c.Tile(); t != nil {
+				pos = t.Pos
+			}
+			write(c.HP, pos[0], pos[1], c.RemainingPoints, c.SpentPoints)
+			if s.Timeline != nil {
+				write(s.Timeline.CT(c))
+			}
+			names := make([]string, 0, len(c.States))
+			for name := range c.States {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				st := c.States[name]
+				h.Write([]byte(name))
+				write(st.Duration, st.Stacks)
+			}
+			h.Write([]byte(c.Facing))
+			for _, cs := range c.Consumables {
+				write(cs.Count)
+			}
+		}
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,63 @@
+package tiled
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	s, c, en := setupStrategyTest()
+	s.Seed = 42
+	s.DefaultStrategy = &Strategy{Evaluators: []Evaluator{DistanceToObjective{Weight: 1}}}
+	c.Sight = 1
+	en.RemainingPoints = 3
+	r := NewRecorder(s)
+	r.Start()
+	r.AutoAction(c.Party)
+	r.TurnOver()
+	r.MoveTo(en, s.Board.TileAt[Pos{1, 2}])
+	r.Undo()
+	r.Step(en, "W")
+	r.TurnOver()
+	buf := &bytes.Buffer{}
+	if err := r.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+
+	rp, err := ReadReplay(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, c2, en2 := setupStrategyTest()
+	s2.DefaultStrategy = &Strategy{Evaluators: []Evaluator{DistanceToObjective{Weight: 1}}}
+	c2.Sight = 1
+	en2.RemainingPoints = 3
+	if err := rp.Play(s2); err != nil {
+		t.Fatal(err)
+	}
+	if s2.Hash() != s.Hash() || c2.Tile().Pos != c.Tile().Pos || en2.Tile().Pos != en.Tile().Pos {
+		t.Fatalf("replayed stage is different from the recorded one")
+	}
+
+	// a stage set up differently should be detected.
+	s3, _, en3 := setupStrategyTest()
+	s3.DefaultStrategy = &Strategy{Evaluators: []Evaluator{DistanceToObjective{Weight: 1}}}
+	en3.RemainingPoints = 3
+	en3.HP = 9
+	if err := rp.Play(s3); err == nil {
+		t.Fatalf("desync should be detected")
+	}
+}
+
+func TestHashRand(t *testing.T) {
+	s, _, _ := setupStrategyTest()
+	s.Seed = 42
+	s.Start()
+	h := s.Hash()
+	s.Rand().Intn(100)
+	if s.Hash() == h {
+		t.Fatalf("hash should change by random numbers drawn")
+	}
+}
@@ -0,0 +1,883 @@
+package tiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// SaveVersion is the version of save files written by Game.Save.
+const SaveVersion = 1
+
+// Functions cannot be saved. Parts of a game having functions are saved by
+// their names, and rebuilt on load by functions registered with the names.
+var (
+	boardKinds        = make(map[string]func(width, height int) *Board)
+	classes           = make(map[string]func(c *Character) *Class)
+	stateEffects      = make(map[string]func(c *Character, st *State))
+	consumableEffects = make(map[string]func(ev *CharacterEvent))
+)
+
+// RegisterBoard registers a board constructor by the kind of boards it creates. See Board.Kind.
+func RegisterBoard(kind string, fn func(width, height int) *Board) {
+	boardKinds[kind] = fn
+}
+
+// RegisterClass registers a class constructor by the name of classes it creates. See Class.Name.
+func RegisterClass(name string, fn func(c *Character) *Class) {
+	classes[name] = fn
+}
+
+// RegisterStateEffect registers an effect of states having the name.
+func RegisterStateEffect(name string, fn func(c *Character, st *State)) {
+	stateEffects[name] = fn
+}
+
+// RegisterConsumableEffect registers an effect of consumables having the name.
+func RegisterConsumableEffect(name string, fn func(ev *CharacterEvent)) {
+	consumableEffects[name] = fn
+}
+
+// Save writes the game, including the stage in progress.
+//
+// Every object is saved once with its ID, and pointers to it are saved as the ID.
+// Strategies, actions, own skills of characters and hooks and victory conditions
+// of the stage are not saved, they should be set again after Load.
+// Commands done in the stage cannot be undone after Load.
+func (g *Game) Save(w io.Writer) error {
+	sv := newSaver()
+	f, err := sv.game(g)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(f)
+}
+
+// Load reads a game written by Save, and replaces the game with it.
+func (g *Game) Load(r io.Reader) error {
+	f := &saveFile{}
+	if err := json.NewDecoder(r).Decode(f); err != nil {
+		return err
+	}
+	if f.Version != SaveVersion {
+		return fmt.Errorf("unsupported save version: %v", f.Version)
+	}
+	ld := &loader{f: f}
+	return ld.game(g)
+}
+
+type saveFile struct {
+	Version        int
+	EquipmentParts []string
+	ItemTypes      []string
+	BaseTiles      []*BaseTile
+	Boards         []savedBoard
+	Items          []*Item
+	Consumables    []savedConsumable
+	Characters     []savedCharacter
+	Parties        []savedParty
+	Fields         []savedField
+	Players        []savedPlayer
+	World          *savedWorld `json:",omitempty"`
+	Stage          *savedStage `json:",omitempty"`
+}
+
+type savedBoard struct {
+	Kind      string
+	Width     int
+	Height    int
+	CornerCut CornerCut `json:",omitempty"`
+	Tiles     []savedTile
+}
+
+type savedTile struct {
+	Pos Pos
+	// Base is ID of the base tile, or -1.
+	Base   int
+	Opaque bool   `json:",omitempty"`
+	Hazard string `json:",omitempty"`
+	// Occupier is ID of the character on the tile, or -1.
+	Occupier int
+	Ways     []savedWay
+}
+
+type savedWay struct {
+	Name  string
+	Cost  int
+	Ledge bool `json:",omitempty"`
+}
+
+type savedCharacter struct {
+	Party int
+	Class string `json:",omitempty"`
+	// Board is ID of the board where Origin is, or -1.
+	Board           int
+	Origin          Pos
+	Moves           []string
+	States          []savedState
+	Equipments      map[string]int
+	Items           []int
+	Consumables     []int
+	MaxPoints       int
+	RemainingPoints int
+	SpentPoints     int
+	AttackDirs      [][]string
+	AttackPower     int
+	HP              int
+	MaxHP           int
+	Sight           int
+	Movement        string `json:",omitempty"`
+	Speed           int
+	Facing          string
+}
+
+// savedState and savedConsumable are saved with whether they had an effect,
+// so a missing effect is found on load.
+type savedState struct {
+	State
+	HasEffect bool `json:",omitempty"`
+}
+
+type savedConsumable struct {
+	Consumable
+	HasEffect bool `json:",omitempty"`
+}
+
+type savedParty struct {
+	Characters []int
+	NPC        bool
+	Relations  map[int]Relation
+	// World reports whether the party is in the game's world.
+	World bool
+}
+
+type savedField struct {
+	Board int
+	PC    int
+	NPCs  []int
+}
+
+type savedPlayer struct {
+	Field int
+}
+
+type savedWorld struct {
+	Time      time.Time
+	FPS       int
+	TimeScale float64
+	Paused    bool
+	// Player is index of the player in Game.Players, or -1.
+	Player int
+	Fields map[string]int
+}
+
+type savedStage struct {
+	Board           int
+	Parties         []int
+	ActiveParty     int
+	WaitedParties   []int
+	Rotation        int
+	ActiveCharacter int
+	Timeline        *savedTimeline `json:",omitempty"`
+	MaxTurns        int
+	FlankDamage     float64
+	BackDamage      float64
+	CollisionDamage int
+	Seed            int64
+	// RandSeed and RandDraws are the seed of random numbers of the stage, and how many numbers are drawn.
+	RandSeed  int64
+	RandDraws int64
+	Turn      int
+	Seen      map[int][]Pos
+}
+
+type savedTimeline struct {
+	Full  int
+	CT    map[int]int
+	Acted []int
+}
+
+// saver gives IDs to objects of a game in the order it meets them.
+type saver struct {
+	f      *saveFile
+	boards map[*Board]int
+	bases  map[*BaseTile]int
+	items  map[*Item]int
+	// consumables are shared by characters having the same pointer.
+	consumables map[*Consumable]int
+	chars       map[*Character]int
+	// charList is characters by their IDs. They are saved after every ID is given.
+	charList  []*Character
+	parties   map[*Party]int
+	partyList []*Party
+	fields    map[*Field]int
+}
+
+func newSaver() *saver {
+	return &saver{
+		f:           &saveFile{Version: SaveVersion},
+		boards:      make(map[*Board]int),
+		bases:       make(map[*BaseTile]int),
+		items:       make(map[*Item]int),
+		consumables: make(map[*Consumable]int),
+		chars:       make(map[*Character]int),
+		parties:     make(map[*Party]int),
+		fields:      make(map[*Field]int),
+	}
+}
+
+func (sv *saver) game(g *Game) (*saveFile, error) {
+	f := sv.f
+	f.EquipmentParts = g.EquipmentParts
+	f.ItemTypes = g.ItemTypes
+	for _, p := range g.Players {
+		f.Players = append(f.Players, savedPlayer{Field: sv.field(p.Field)})
+	}
+	if w := g.World; w != nil {
+		sw := &savedWorld{
+			Time:      w.Time,
+			FPS:       w.FPS,
+			TimeScale: w.scale(),
+			Paused:    w.Paused(),
+			Player:    -1,
+			Fields:    make(map[string]int),
+		}
+		for i := range g.Players {
+			if w.Player == &g.Players[i] {
+				sw.Player = i
+			}
+		}
+		if sw.Player < 0 && w.Player != nil {
+			return nil, fmt.Errorf("player of the world is not a player of the game")
+		}
+		for name, fd := range w.Field {
+			sw.Fields[name] = sv.field(fd)
+		}
+		f.World = sw
+	}
+	if s := g.Stage; s != nil {
+		f.Stage = sv.stage(s)
+	}
+	// characters could meet parties and boards, and they could meet characters.
+	for ci, pi := 0, 0; ci < len(sv.charList) || pi < len(sv.partyList); {
+		for ; ci < len(sv.charList); ci++ {
+			sv.character(sv.charList[ci])
+		}
+		for ; pi < len(sv.partyList); pi++ {
+			p := sv.partyList[pi]
+			f.Parties[pi].World = p.World != nil && p.World == g.World
+			for q, r := range p.Relations {
+				id := sv.partyID(q)
+				f.Parties[pi].Relations[id] = r
+			}
+		}
+	}
+	for b, id := range sv.boards {
+		if b.Kind == "" || boardKinds[b.Kind] == nil {
+			return nil, fmt.Errorf("board kind not registered: %q", b.Kind)
+		}
+		f.Boards[id] = sv.board(b)
+	}
+	for i, c := range sv.charList {
+		if c.Class != nil && classes[c.Class.Name] == nil {
+			return nil, fmt.Errorf("class of character %v not registered: %q", i, c.Class.Name)
+		}
+		for name, st := range c.States {
+			if st.Effect != nil && stateEffects[name] == nil {
+				return nil, fmt.Errorf("effect of state %q of character %v not registered", name, i)
+			}
+		}
+	}
+	for _, cs := range f.Consumables {
+		if cs.HasEffect && consumableEffects[cs.Name] == nil {
+			return nil, fmt.Errorf("effect of consumable %q not registered", cs.Name)
+		}
+	}
+	return f, nil
+}
+
+func (sv *saver) board(b *Board) savedBoard {
+	sb := savedBoard{Kind: b.Kind, Width: b.Width, Height: b.Height, CornerCut: b.CornerCut}
+	for _, pos := range tilePoses(b) {
+		t := b.TileAt[pos]
+		st := savedTile{Pos: pos, Base: sv.baseID(t.Base), Opaque: t.Opaque, Hazard: t.Hazard, Occupier: sv.charID(t.Occupier)}
+		for _, w := range t.Ways {
+			st.Ways = append(st.Ways, savedWay{Name: w.Name, Cost: w.Cost, Ledge: w.Ledge})
+		}
+		sb.Tiles = append(sb.Tiles, st)
+	}
+	return sb
+}
+
+func (sv *saver) boardID(b *Board) int {
+	if b == nil {
+		return -1
+	}
+	if id, ok := sv.boards[b]; ok {
+		return id
+	}
+	id := len(sv.f.Boards)
+	sv.boards[b] = id
+	// filled after every character is met.
+	sv.f.Boards = append(sv.f.Boards, savedBoard{})
+	for _, pos := range tilePoses(b) {
+		sv.charID(b.TileAt[pos].Occupier)
+	}
+	return id
+}
+
+// tilePoses returns positions of tiles in the board, in a stable order.
+func tilePoses(b *Board) []Pos {
+	poses := make([]Pos, 0, len(b.TileAt))
+	for pos := range b.TileAt {
+		poses = append(poses, pos)
+	}
+	return CreateArea(poses).Poses()
+}
+
+func (sv *saver) baseID(b *BaseTile) int {
+	if b == nil {
+		return -1
+	}
+	if id, ok := sv.bases[b]; ok {
+		return id
+	}
+	id := len(sv.f.BaseTiles)
+	sv.bases[b] = id
+	sv.f.BaseTiles = append(sv.f.BaseTiles, b)
+	return id
+}
+
+func (sv *saver) consumableID(cs *Consumable) int {
+	if id, ok := sv.consumables[cs]; ok {
+		return id
+	}
+	id := len(sv.f.Consumables)
+	sv.consumables[cs] = id
+	sv.f.Consumables = append(sv.f.Consumables, savedConsumable{Consumable: *cs, HasEffect: cs.Effect != nil})
+	return id
+}
+
+func (sv *saver) itemID(it *Item) int {
+	if id, ok := sv.items[it]; ok {
+		return id
+	}
+	id := len(sv.f.Items)
+	sv.items[it] = id
+	sv.f.Items = append(sv.f.Items, it)
+	return id
+}
+
+func (sv *saver) charID(c *Character) int {
+	if c == nil {
+		return -1
+	}
+	if id, ok := sv.chars[c]; ok {
+		return id
+	}
+	id := len(sv.charList)
+	sv.chars[c] = id
+	sv.charList = append(sv.charList, c)
+	sv.f.Characters = append(sv.f.Characters, savedCharacter{})
+	return id
+}
+
+func (sv *saver) partyID(p *Party) int {
+	if p == nil {
+		return -1
+	}
+	if id, ok := sv.parties[p]; ok {
+		return id
+	}
+	id := len(sv.f.Parties)
+	sv.parties[p] = id
+	sv.partyList = append(sv.partyList, p)
+	// relations are filled after every character is met.
+	sp := savedParty{NPC: p.NPC, Relations: make(map[int]Relation)}
+	for _, c := range p.Characters {
+		sp.Characters = append(sp.Characters, sv.charID(c))
+	}
+	sv.f.Parties = append(sv.f.Parties, sp)
+	return id
+}
+
+func (sv *saver) field(fd *Field) int {
+	if fd == nil {
+		return -1
+	}
+	if id, ok := sv.fields[fd]; ok {
+		return id
+	}
+	id := len(sv.f.Fields)
+	sv.fields[fd] = id
+	sf := savedField{Board: sv.boardID(fd.Board), PC: sv.charID(fd.PC)}
+	for _, c := range fd.NPCs {
+		sf.NPCs = append(sf.NPCs, sv.charID(c))
+	}
+	sv.f.Fields = append(sv.f.Fields, sf)
+	return id
+}
+
+func (sv *saver) character(c *Character) {
+	sc := savedCharacter{
+		Party:           sv.partyID(c.Party),
+		Board:           -1,
+		Equipments:      make(map[string]int),
+		MaxPoints:       c.MaxPoints,
+		RemainingPoints: c.RemainingPoints,
+		SpentPoints:     c.SpentPoints,
+		AttackDirs:      c.AttackDirs,
+		AttackPower:     c.AttackPower,
+		HP:              c.HP,
+		MaxHP:           c.MaxHP,
+		Sight:           c.Sight,
+		Movement:        c.Movement,
+		Speed:           c.Speed,
+		Facing:          c.Facing,
+	}
+	if c.Class != nil {
+		sc.Class = c.Class.Name
+	}
+	if c.Origin != nil {
+		sc.Board = sv.boardID(c.Origin.Board)
+		sc.Origin = c.Origin.Pos
+	}
+	for _, w := range c.Moves {
+		sc.Moves = append(sc.Moves, w.Name)
+	}
+	names := make([]string, 0, len(c.States))
+	for name := range c.States {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		st := c.States[name]
+		sc.States = append(sc.States, savedState{State: *st, HasEffect: st.Effect != nil})
+	}
+	parts := make([]string, 0, len(c.Equipments))
+	for part := range c.Equipments {
+		parts = append(parts, part)
+	}
+	sort.Strings(parts)
+	for _, part := range parts {
+		sc.Equipments[part] = sv.itemID(c.Equipments[part])
+	}
+	for _, it := range c.Items {
+		sc.Items = append(sc.Items, sv.itemID(it))
+	}
+	for _, cs := range c.Consumables {
+		sc.Consumables = append(sc.Consumables, sv.consumableID(cs))
+	}
+	sv.f.Characters[sv.chars[c]] = sc
+}
+
+func (sv *saver) stage(s *Stage) *savedStage {
+	ss := &savedStage{
+		MaxTurns:        s.MaxTurns,
+		FlankDamage:     s.FlankDamage,
+		BackDamage:      s.BackDamage,
+		CollisionDamage: s.CollisionDamage,
+		Seed:            s.Seed,
+		Turn:            s.turn,
+		Seen:            make(map[int][]Pos),
+	}
+	for _, p := range s.Parties {
+		ss.Parties = append(ss.Parties, sv.partyID(p))
+	}
+	ss.Board = sv.boardID(s.Board)
+	ss.ActiveParty = sv.partyID(s.ActiveParty)
+	ss.Rotation = sv.partyID(s.rotation)
+	ss.ActiveCharacter = sv.charID(s.ActiveCharacter)
+	for _, p := range s.WaitedParties {
+		ss.WaitedParties = append(ss.WaitedParties, sv.partyID(p))
+	}
+	for p, a := range s.seen {
+		ss.Seen[sv.partyID(p)] = a.Poses()
+	}
+	// random numbers not drawn yet will be seeded by Seed.
+	ss.RandSeed = s.Seed
+	if s.src != nil {
+		ss.RandSeed, ss.RandDraws = s.src.seed, s.src.n
+	}
+	if tl := s.Timeline; tl != nil {
+		st := &savedTimeline{Full: tl.Full, CT: make(map[int]int)}
+		for c, ct := range tl.ct {
+			st.CT[sv.charID(c)] = ct
+		}
+		for c := range tl.acted {
+			st.Acted = append(st.Acted, sv.charID(c))
+		}
+		sort.Ints(st.Acted)
+		ss.Timeline = st
+	}
+	return ss
+}
+
+// loader rebuilds objects of a save file.
+type loader struct {
+	f           *saveFile
+	boards      []*Board
+	items       []*Item
+	consumables []*Consumable
+	chars       []*Character
+	parties     []*Party
+	fields      []*Field
+}
+
+func (ld *loader) game(g *Game) error {
+	f := ld.f
+	for i, sb := range f.Boards {
+		fn := boardKinds[sb.Kind]
+		if fn == nil {
+			return fmt.Errorf("board kind not registered: %q", sb.Kind)
+		}
+		b := fn(sb.Width, sb.Height)
+		b.CornerCut = sb.CornerCut
+		// tiles and ways removed from the saved board are removed again.
+		saved := make(map[Pos]bool, len(sb.Tiles))
+		for _, st := range sb.Tiles {
+			saved[st.Pos] = true
+		}
+		for pos := range b.TileAt {
+			if !saved[pos] {
+				delete(b.TileAt, pos)
+			}
+		}
+		for _, st := range sb.Tiles {
+			t := b.TileAt[st.Pos]
+			if t == nil {
+				return fmt.Errorf("board %v doesn't have tile at %v", i, st.Pos)
+			}
+			if st.Base != -1 {
+				if st.Base < 0 || st.Base >= len(f.BaseTiles) {
+					return fmt.Errorf("invalid base tile id: %v", st.Base)
+				}
+				t.Base = f.BaseTiles[st.Base]
+			}
+			t.Opaque = st.Opaque
+			t.Hazard = st.Hazard
+			ways := make([]*Way, 0, len(st.Ways))
+			for _, sw := range st.Ways {
+				if w := t.Way(sw.Name); w != nil && w.To == b.TileAt[w.To.Pos] {
+					w.Cost = sw.Cost
+					w.Ledge = sw.Ledge
+					ways = append(ways, w)
+				}
+			}
+			t.Ways = ways
+		}
+		ld.boards = append(ld.boards, b)
+	}
+	ld.items = f.Items
+	for _, sc := range f.Consumables {
+		cs := sc.Consumable
+		if sc.HasEffect {
+			cs.Effect = consumableEffects[cs.Name]
+			if cs.Effect == nil {
+				return fmt.Errorf("effect of consumable not registered: %q", cs.Name)
+			}
+		}
+		ld.consumables = append(ld.consumables, &cs)
+	}
+	for range f.Characters {
+		ld.chars = append(ld.chars, &Character{})
+	}
+	for range f.Parties {
+		ld.parties = append(ld.parties, &Party{})
+	}
+	for i, sc := range f.Characters {
+		if err := ld.character(ld.chars[i], sc); err != nil {
+			return fmt.Errorf("character %v: %v", i, err)
+		}
+	}
+	for i, sb := range f.Boards {
+		for _, st := range sb.Tiles {
+			c, err := ld.char(st.Occupier)
+			if err != nil {
+				return err
+			}
+			ld.boards[i].TileAt[st.Pos].Occupier = c
+		}
+	}
+	var world *World
+	if f.World != nil {
+		world = &World{}
+	}
+	for i, sp := range f.Parties {
+		p := ld.parties[i]
+		p.NPC = sp.NPC
+		for _, id := range sp.Characters {
+			c, err := ld.char(id)
+			if err != nil {
+				return err
+			}
+			p.Characters = append(p.Characters, c)
+		}
+		for id, r := range sp.Relations {
+			q, err := ld.party(id)
+			if err != nil {
+				return err
+			}
+			p.SetRelation(q, r)
+		}
+		if sp.World {
+			p.World = world
+		}
+	}
+	for _, sf := range f.Fields {
+		fd := &Field{}
+		var err error
+		if fd.Board, err = ld.board(sf.Board); err != nil {
+			return err
+		}
+		if fd.PC, err = ld.char(sf.PC); err != nil {
+			return err
+		}
+		for _, id := range sf.NPCs {
+			c, err := ld.char(id)
+			if err != nil {
+				return err
+			}
+			fd.NPCs = append(fd.NPCs, c)
+		}
+		ld.fields = append(ld.fields, fd)
+	}
+	loaded := Game{EquipmentParts: f.EquipmentParts, ItemTypes: f.ItemTypes, World: world}
+	for _, sp := range f.Players {
+		fd, err := ld.field(sp.Field)
+		if err != nil {
+			return err
+		}
+		loaded.Players = append(loaded.Players, Player{Field: fd})
+	}
+	if sw := f.World; sw != nil {
+		world.Time = sw.Time
+		world.FPS = sw.FPS
+		world.timeScale = sw.TimeScale
+		world.paused = sw.Paused
+		world.Field = make(map[string]*Field)
+		if sw.Player >= 0 {
+			if sw.Player >= len(loaded.Players) {
+				return fmt.Errorf("invalid player: %v", sw.Player)
+			}
+			world.Player = &loaded.Players[sw.Player]
+		}
+		for name, id := range sw.Fields {
+			fd, err := ld.field(id)
+			if err != nil {
+				return err
+			}
+			world.Field[name] = fd
+		}
+	}
+	if f.Stage != nil {
+		s, err := ld.stage(f.Stage)
+		if err != nil {
+			return err
+		}
+		loaded.Stage = s
+	}
+	*g = loaded
+	return nil
+}
+
+func (ld *loader) character(c *Character, sc savedCharacter) error {
+	var err error
+	if c.Party, err = ld.party(sc.Party); err != nil {
+		return err
+	}
+	if sc.Board >= 0 {
+		b, err := ld.board(sc.Board)
+		if err != nil {
+			return err
+		}
+		c.Origin = b.TileAt[sc.Origin]
+		if c.Origin == nil {
+			return fmt.Errorf("no tile at origin %v", sc.Origin)
+		}
+		at := c.Origin
+		for _, name := range sc.Moves {
+			w := at.Way(name)
+			if w == nil {
+				return fmt.Errorf("no way %v from %v", name, at.Pos)
+			}
+			c.Moves = append(c.Moves, *w)
+			at = w.To
+		}
+	}
+	if sc.Class != "" {
+		fn := classes[sc.Class]
+		if fn == nil {
+			return fmt.Errorf("class not registered: %q", sc.Class)
+		}
+		c.Class = fn(c)
+	}
+	for _, ss := range sc.States {
+		st := ss.State
+		if ss.HasEffect {
+			st.Effect = stateEffects[st.Name]
+			if st.Effect == nil {
+				return fmt.Errorf("effect of state not registered: %q", st.Name)
+			}
+		}
+		if c.States == nil {
+			c.States = make(map[string]*State)
+		}
+		c.States[st.Name] = &st
+	}
+	for part, id := range sc.Equipments {
+		it, err := ld.item(id)
+		if err != nil {
+			return err
+		}
+		if c.Equipments == nil {
+			c.Equipments = make(map[string]*Item)
+		}
+		c.Equipments[part] = it
+	}
+	for _, id := range sc.Items {
+		it, err := ld.item(id)
+		if err != nil {
+			return err
+		}
+		c.Items = append(c.Items, it)
+	}
+	for _, id := range sc.Consumables {
+		if id < 0 || id >= len(ld.consumables) {
+			return fmt.Errorf("invalid consumable id: %v", id)
+		}
+		c.Consumables = append(c.Consumables, ld.consumables[id])
+	}
+	c.MaxPoints = sc.MaxPoints
+	c.RemainingPoints = sc.RemainingPoints
+	c.SpentPoints = sc.SpentPoints
+	c.AttackDirs = sc.AttackDirs
+	c.AttackPower = sc.AttackPower
+	c.HP = sc.HP
+	c.MaxHP = sc.MaxHP
+	c.Sight = sc.Sight
+	c.Movement = sc.Movement
+	c.Speed = sc.Speed
+	c.Facing = sc.Facing
+	return nil
+}
+
+func (ld *loader) stage(ss *savedStage) (*Stage, error) {
+	s := &Stage{
+		MaxTurns:        ss.MaxTurns,
+		FlankDamage:     ss.FlankDamage,
+		BackDamage:      ss.BackDamage,
+		CollisionDamage: ss.CollisionDamage,
+		Seed:            ss.Seed,
+		turn:            ss.Turn,
+	}
+	var err error
+	if s.Board, err = ld.board(ss.Board); err != nil {
+		return nil, err
+	}
+	if s.ActiveParty, err = ld.party(ss.ActiveParty); err != nil {
+		return nil, err
+	}
+	if s.rotation, err = ld.party(ss.Rotation); err != nil {
+		return nil, err
+	}
+	if s.ActiveCharacter, err = ld.char(ss.ActiveCharacter); err != nil {
+		return nil, err
+	}
+	for _, id := range ss.Parties {
+		p, err := ld.party(id)
+		if err != nil {
+			return nil, err
+		}
+		s.Parties = append(s.Parties, p)
+	}
+	for _, id := range ss.WaitedParties {
+		p, err := ld.party(id)
+		if err != nil {
+			return nil, err
+		}
+		s.WaitedParties = append(s.WaitedParties, p)
+	}
+	if len(ss.Seen) != 0 {
+		s.seen = make(map[*Party]Area)
+	}
+	for id, poses := range ss.Seen {
+		p, err := ld.party(id)
+		if err != nil {
+			return nil, err
+		}
+		s.seen[p] = CreateArea(poses)
+	}
+	if ss.RandDraws != 0 || ss.RandSeed != ss.Seed {
+		s.seed(ss.RandSeed, ss.RandDraws)
+	}
+	if st := ss.Timeline; st != nil {
+		tl := NewTimeline(st.Full)
+		tl.reset()
+		for id, ct := range st.CT {
+			c, err := ld.char(id)
+			if err != nil {
+				return nil, err
+			}
+			tl.ct[c] = ct
+		}
+		for _, id := range st.Acted {
+			c, err := ld.char(id)
+			if err != nil {
+				return nil, err
+			}
+			tl.acted[c] = true
+		}
+		s.Timeline = tl
+	}
+	return s, nil
+}
+
+func (ld *loader) board(id int) (*Board, error) {
+	if id == -1 {
+		return nil, nil
+	}
+	if id < 0 || id >= len(ld.boards) {
+		return nil, fmt.Errorf("invalid board id: %v", id)
+	}
+	return ld.boards[id], nil
+}
+
+func (ld *loader) item(id int) (*Item, error) {
+	if id < 0 || id >= len(ld.items) {
+		return nil, fmt.Errorf("invalid item id: %v", id)
+	}
+	return ld.items[id], nil
+}
+
+func (ld *loader) char(id int) (*Character, error) {
+	if id == -1 {
+		return nil, nil
+	}
+	if id < 0 || id >= len(ld.chars) {
+		return nil, fmt.Errorf("invalid character id: %v", id)
+	}
+	return ld.chars[id], nil
+}
+
+func (ld *loader) party(id int) (*Party, error) {
+	if id == -1 {
+		return nil, nil
+	}
+	if id < 0 || id >= len(ld.parties) {
+		return nil, fmt.Errorf("invalid party id: %v", id)
+	}
+	return ld.parties[id], nil
+}
+
+func (ld *loader) field(id int) (*Field, error) {
+	if id == -1 {
+		return nil, nil
+	}
+	if id < 0 || id >= len(ld.fields) {
+		return nil, fmt.Errorf("invalid field id: %v", id)
+	}
+	return ld.fields[id], nil
+}
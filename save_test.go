@@ -0,0 +1,193 @@
+package tiled
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func init() {
+	RegisterBoard("test", func(width, height int) *Board {
+		rows := make([]string, height)
+		for i := range rows {
+			rows[i] = strings.Repeat(".", width)
+		}
+		b := newTestBoard(rows)
+		b.Kind = "test"
+		b.Width, b.Height = width, height
+		return b
+	})
+	RegisterConsumableEffect("potion", Heal)
+}
+
+func TestSaveLoad(t *testing.T) {
+	b := newTestBoard([]string{
+		"....",
+		"....",
+	})
+	b.Kind = "test"
+	b.Width, b.Height = 4, 2
+	b.TileAt[Pos{3, 1}].Hazard = "lava"
+	b.TileAt[Pos{2, 0}].Way("E").Cost = 3
+	forest := &BaseTile{Name: "forest", Defense: 1}
+	b.TileAt[Pos{2, 1}].Base = forest
+	b.TileAt[Pos{3, 1}].Base = forest
+	a, e := newTestParty(10, 10), newTestParty(10)
+	e.NPC = true
+	// n is only known by relation of e.
+	n := newTestParty(5)
+	e.SetRelation(n, Neutral)
+	c := a.Characters[0]
+	c.MaxPoints, c.RemainingPoints = 5, 5
+	c.Movement = Swim
+	c.Place(b.TileAt[Pos{0, 0}])
+	a.Characters[1].Place(b.TileAt[Pos{0, 1}])
+	e.Characters[0].Place(b.TileAt[Pos{3, 0}])
+	sword := &Item{Name: "sword", Type: "sword", Part: "hand", Modifier: Stats{AttackPower: 2}}
+	g := &Game{EquipmentParts: []string{"hand"}, ItemTypes: []string{"sword"}}
+	c.Items = []*Item{sword}
+	if err := g.Equip(c, sword); err != nil {
+		t.Fatal(err)
+	}
+	c.Consumables = []*Consumable{{Name: "potion", Count: 2, Value: 3, Effect: Heal}}
+	// the party shares its potions.
+	a.Characters[1].Consumables = c.Consumables
+	// a wall between two tiles.
+	t31 := b.TileAt[Pos{3, 1}]
+	t31.Ways = t31.Ways[1:]
+	c.AddState(Bleed(1, 3))
+	s := &Stage{Board: b, Parties: []*Party{a, e}, Seed: 7, Timeline: NewTimeline(100)}
+	for _, ch := range []*Character{c, a.Characters[1], e.Characters[0]} {
+		ch.Speed = 10
+	}
+	g.Stage = s
+	s.Start()
+	s.Step(c, *c.Tile().Way("E"))
+	s.Step(c, *c.Tile().Way("E"))
+	s.Rand().Intn(10)
+	s.Rand().Float64()
+	want := *s.src
+
+	buf := &bytes.Buffer{}
+	if err := g.Save(buf); err != nil {
+		t.Fatal(err)
+	}
+	saved := buf.String()
+	if *s.src != want {
+		t.Fatalf("save should not change random numbers of the stage")
+	}
+	hash := s.Hash()
+	r := s.Rand().Int63()
+
+	ld := &Game{}
+	if err := ld.Load(strings.NewReader(saved)); err != nil {
+		t.Fatal(err)
+	}
+	ls := ld.Stage
+	if ls.Hash() != hash {
+		t.Fatalf("loaded stage is different from the saved one")
+	}
+	if ls.Rand().Int63() != r {
+		t.Fatalf("loaded stage should have the same random numbers")
+	}
+	la, le := ls.Parties[0], ls.Parties[1]
+	lc := la.Characters[0]
+	if lc.Party != la {
+		t.Fatalf("party of the character is not rebuilt")
+	}
+	if ls.ActiveParty != la || ls.ActiveCharacter != lc {
+		t.Fatalf("active party and character are not rebuilt: %v %v", ls.ActiveParty == la, ls.ActiveCharacter == lc)
+	}
+	if lc.Tile() != ls.Board.TileAt[Pos{2, 0}] || lc.Tile().Occupier != lc || len(lc.Moves) != 2 || lc.SpentPoints != 2 {
+		t.Fatalf("moves in the turn are not loaded")
+	}
+	if ls.Board.TileAt[Pos{3, 1}].Hazard != "lava" || ls.Board.TileAt[Pos{2, 0}].Way("E").Cost != 3 {
+		t.Fatalf("tiles are not loaded")
+	}
+	if lb := ls.Board.TileAt[Pos{2, 1}].Base; lb == nil || lb.Name != "forest" || lb != ls.Board.TileAt[Pos{3, 1}].Base {
+		t.Fatalf("base tiles are not loaded")
+	}
+	if lt := ls.Board.TileAt[Pos{3, 1}]; len(lt.Ways) != len(t31.Ways) || lt.Way("N") != nil {
+		t.Fatalf("removed way should not be loaded: got %v ways", len(lt.Ways))
+	}
+	if la.Characters[1].Consumables[0] != lc.Consumables[0] {
+		t.Fatalf("shared consumable should be loaded shared")
+	}
+	if lc.Movement != Swim {
+		t.Fatalf("movement: want %v, got %v", Swim, lc.Movement)
+	}
+	if !le.NPC || le.Relation(la) != Hostile || len(le.Relations) != 1 {
+		t.Fatalf("parties are not loaded")
+	}
+	for ln, r := range le.Relations {
+		if r != Neutral || len(ln.Characters) != 1 || ln.Characters[0].Party != ln || ln.Characters[0].HP != 5 {
+			t.Fatalf("party known by relation is not loaded")
+		}
+	}
+	if lc.Stats().AttackPower != 2 || lc.Equipments["hand"].Name != "sword" || len(lc.Items) != 0 {
+		t.Fatalf("equipments are not loaded")
+	}
+	if ls.Timeline.CT(lc) != s.Timeline.CT(c) {
+		t.Fatalf("timeline is not loaded")
+	}
+	// effects are rebuilt.
+	lc.AdvanceStates(PerTurn)
+	if lc.HP != 9 {
+		t.Fatalf("bleed: want hp 9, got %v", lc.HP)
+	}
+	ls.Use(lc, lc.Consumable("potion"))
+	if lc.HP != 12 {
+		t.Fatalf("potion: want hp 12, got %v", lc.HP)
+	}
+	// games loaded from the same save are saved the same.
+	saves := make([]string, 2)
+	for i := range saves {
+		g := &Game{}
+		g.Load(strings.NewReader(saved))
+		buf := &bytes.Buffer{}
+		if err := g.Save(buf); err != nil {
+			t.Fatal(err)
+		}
+		saves[i] = buf.String()
+	}
+	if saves[0] != saves[1] {
+		t.Fatalf("saves are not stable")
+	}
+	if err := ld.Load(strings.NewReader(strings.Replace(saved, `"Version":1`, `"Version":99`, 1))); err == nil {
+		t.Fatalf("should not load unsupported version")
+	}
+}
+
+func TestSaveUnregistered(t *testing.T) {
+	a := newTestParty(10)
+	c := a.Characters[0]
+	g := &Game{Stage: &Stage{Parties: []*Party{a}}}
+	c.AddState(State{Name: "curse", Unit: PerTurn, Duration: 3, Effect: func(c *Character, st *State) {}})
+	if err := g.Save(&bytes.Buffer{}); err == nil {
+		t.Fatalf("save with unregistered state effect: want error, got nil")
+	}
+	c.RemoveState("curse")
+	c.Consumables = []*Consumable{{Name: "elixir", Count: 1, Effect: Heal}}
+	if err := g.Save(&bytes.Buffer{}); err == nil {
+		t.Fatalf("save with unregistered consumable effect: want error, got nil")
+	}
+	c.Consumables = nil
+	if err := g.Save(&bytes.Buffer{}); err != nil {
+		t.Fatalf("save: want no error, got %v", err)
+	}
+}
+
+func TestSaveRandBeforeDraw(t *testing.T) {
+	g := &Game{Stage: &Stage{Parties: []*Party{newTestParty(10)}, Seed: 42}}
+	buf := &bytes.Buffer{}
+	if err := g.Save(buf); err != nil {
+		t.Fatal(err)
+	}
+	ld := &Game{}
+	if err := ld.Load(buf); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := g.Stage.Rand().Int63(), ld.Stage.Rand().Int63(); got != want {
+		t.Fatalf("random number of a stage saved before drawing: want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,91 @@
+package tiled
+
+// BlocksSight reports whether the tile, or its terrain, blocks sight through it.
+func (t *Tile) BlocksSight() bool {
+	return t.Opaque || t.Base != nil && t.Base.BlocksSight
+}
+
+// LineOfSight reports whether sight from a reaches b without being blocked.
+// Tiles at both ends don't block the sight, so a wall can be seen.
+// Every tile is in line of sight when the board doesn't define Line.
+func (b *Board) LineOfSight(from, to Pos) bool {
+	if b.Line == nil {
+		return true
+	}
+	line := b.Line(from, to)
+	for i := 1; i < len(line)-1; i++ {
+		t := b.TileAt[line[i]]
+		if t == nil || t.BlocksSight() {
+			return false
+		}
+	}
+	return true
+}
+
+// Sees reports whether the character can see the tile.
+// It needs the tile in the character's sight and line of sight.
+func (c *Character) Sees(t *Tile) bool {
+	return c.seesFrom(c.Tile(), t)
+}
+
+// seesFrom reports whether the character could see the tile if it is on the tile at.
+func (c *Character) seesFrom(at, t *Tile) bool {
+	if at == nil || t == nil || c.Dead() {
+		return false
+	}
+	b := at.Board
+	if c.Sight > 0 && b.Distance != nil && b.Distance(at.Pos, t.Pos) > c.Sight {
+		return false
+	}
+	return b.LineOfSight(at.Pos, t.Pos)
+}
+
+// Visible returns the area of the board characters of the party can see now.
+// Visible tiles are remembered by the stage, see Seen.
+func (s *Stage) Visible(p *Party) Area {
+	poses := make([]Pos, 0)
+	for _, t := range s.Board.TileAt {
+		for _, c := range p.Characters {
+			if c.Sees(t) {
+				poses = append(poses, t.Pos)
+				break
+			}
+		}
+	}
+	visible := CreateArea(poses)
+	if s.seen == nil {
+		s.seen = make(map[*Party]Area)
+	}
+	if seen, ok := s.seen[p]; ok {
+		s.seen[p] = seen.Union(visible)
+	} else {
+		s.seen[p] = visible
+	}
+	return visible
+}
+
+// remember updates the area the party has seen by what it sees now.
+// Sight while simulating is not real, so it is not remembered.
+func (s *Stage) remember(p *Party) {
+	if s.Board == nil || p == nil || s.simulating > 0 {
+		return
+	}
+	s.Visible(p)
+}
+
+// Seen returns the area of the board the party has ever seen in the stage,
+// including what they see now.
+func (s *Stage) Seen(p *Party) Area {
+	s.Visible(p)
+	return s.seen[p]
+}
+
+// CanSee reports whether the party can see the tile now.
+func (s *Stage) CanSee(p *Party, t *Tile) bool {
+	for _, c := range p.Characters {
+		if c.Sees(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package tiled
+
+import (
+	"testing"
+)
+
+// testLine returns positions on a horizontal or vertical line.
+func testLine(a, b Pos) []Pos {
+	poses := []Pos{a}
+	for p := a; p != b; {
+		for i := range p {
+			if p[i] < b[i] {
+				p[i]++
+			} else if p[i] > b[i] {
+				p[i]--
+			}
+		}
+		poses = append(poses, p)
+	}
+	return poses
+}
+
+func TestVisible(t *testing.T) {
+	b := newTestBoard([]string{
+		".....",
+		".....",
+	})
+	b.Line = testLine
+	b.TileAt[Pos{2, 0}].Opaque = true
+	a, e := newTestParty(1), newTestParty(1)
+	s := &Stage{Board: b, Parties: []*Party{a, e}}
+	c := a.Characters[0]
+	c.Place(b.TileAt[Pos{0, 0}])
+	c.Sight = 3
+	e.Characters[0].Place(b.TileAt[Pos{3, 0}])
+	want := []Pos{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {2, 0}, {2, 1}}
+	got := s.Visible(a).Poses()
+	if !samePoses(got, want) {
+		t.Fatalf("visible: want %v, got %v", want, got)
+	}
+	if s.CanSee(a, b.TileAt[Pos{3, 0}]) {
+		t.Fatalf("should not see through the wall")
+	}
+	c.Place(b.TileAt[Pos{4, 1}])
+	want = []Pos{{1, 1}, {2, 0}, {2, 1}, {3, 0}, {3, 1}, {4, 0}, {4, 1}}
+	got = s.Visible(a).Poses()
+	if !samePoses(got, want) {
+		t.Fatalf("visible after move: want %v, got %v", want, got)
+	}
+	if n := s.Seen(a).Len(); n != 10 {
+		t.Fatalf("seen: want 10 tiles, got %v", n)
+	}
+	c.AttackDirs = [][]string{{"W", "W", "W"}, {"S"}}
+	c.Place(b.TileAt[Pos{4, 0}])
+	if n := len(c.AttackableTiles()); n != 1 {
+		t.Fatalf("attackable tiles: want 1, got %v", n)
+	}
+}
+
+func TestRemember(t *testing.T) {
+	b := newTestBoard([]string{
+		".....",
+	})
+	b.Line = testLine
+	a, e := newTestParty(1), newTestParty(1)
+	s := &Stage{Board: b, Parties: []*Party{a, e}}
+	c := a.Characters[0]
+	c.Sight = 1
+	c.MaxPoints, c.RemainingPoints = 3, 3
+	c.Place(b.TileAt[Pos{0, 0}])
+	e.Characters[0].Place(b.TileAt[Pos{4, 0}])
+	s.Start()
+	s.Step(c, *c.Tile().Way("E"))
+	s.Step(c, *c.Tile().Way("E"))
+	// seen before asking for it.
+	if !s.seen[a].Contains(Pos{3, 0}) {
+		t.Fatalf("tiles seen while moving should be remembered")
+	}
+	s.Undo()
+	s.Undo()
+	if !s.seen[a].Contains(Pos{3, 0}) {
+		t.Fatalf("undo should not forget tiles seen")
+	}
+	// placed, not moved.
+	e.Characters[0].Sight = 1
+	e.Characters[0].Place(b.TileAt[Pos{1, 0}])
+	s.TurnOver()
+	if !s.seen[e].Contains(Pos{0, 0}) {
+		t.Fatalf("tiles seen at turn over should be remembered")
+	}
+	// tiles without a board are attackable without line of sight.
+	from, to := &Tile{}, &Tile{}
+	from.Ways = []*Way{{Name: "E", From: from, To: to}}
+	ch := &Character{AttackDirs: [][]string{{"E"}}}
+	if n := len(ch.attackableFrom(from)); n != 1 {
+		t.Fatalf("attackable tiles without a board: want 1, got %v", n)
+	}
+}
+
+// shootSkill damages the character on the tile 2 steps east by 6.
+type shootSkill struct {
+	caster *Character
+}
+
+func (sk shootSkill) Origin() *Tile        { return sk.caster.Tile() }
+func (sk shootSkill) SelectableArea() Area { return CreateArea([]Pos{{2, 0}}) }
+func (sk shootSkill) CastArea(sel Pos) Area {
+	return CreateArea([]Pos{sel})
+}
+func (sk shootSkill) Cast(sel Pos) []CharacterEvent {
+	t := sk.caster.Tile().Board.TileAt[sel]
+	if t == nil || t.Occupier == nil {
+		return nil
+	}
+	return []CharacterEvent{{Character: t.Occupier, Source: sk.caster, On: "attacked", Value: 6, Effect: func(ev *CharacterEvent) {
+		ev.Character.HP -= ev.Value
+	}}}
+}
+
+func TestCastLineOfSight(t *testing.T) {
+	b := newTestBoard([]string{
+		"...",
+		"...",
+	})
+	b.Line = testLine
+	a, e := newTestParty(10, 10), newTestParty(10)
+	c, ally, en := a.Characters[0], a.Characters[1], e.Characters[0]
+	c.Place(b.TileAt[Pos{0, 0}])
+	ally.Place(b.TileAt[Pos{2, 1}])
+	en.Place(b.TileAt[Pos{2, 0}])
+	s := &Stage{Board: b, Parties: []*Party{a, e}}
+	sk := shootSkill{caster: c}
+	b.TileAt[Pos{1, 0}].Opaque = true
+	if s.Cast(sk, Pos{2, 0}) || en.HP != 10 {
+		t.Fatalf("should not cast through a wall: got hp %v", en.HP)
+	}
+	b.TileAt[Pos{1, 0}].Opaque = false
+	// the ally sees the enemy, but the caster doesn't.
+	c.Sight = 1
+	c.Skills = map[string]Skill{"shoot": sk}
+	NewStrategy(1, DamageDealt{Weight: 1}).Action(s, c)
+	if en.HP != 10 {
+		t.Fatalf("should not target an enemy the caster cannot see: got hp %v", en.HP)
+	}
+	if !s.Cast(sk, Pos{2, 0}) || en.HP != 4 {
+		t.Fatalf("cast in line of sight: want hp 4, got %v", en.HP)
+	}
+}
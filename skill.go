@@ -0,0 +1,103 @@
+package tiled
+
+// Skill is an ability of a character which can be cast to a selected position.
+type Skill interface {
+	// Origin is the tile where the skill is cast from. Usually it is the caster's tile.
+	Origin() *Tile
+	// SelectableArea is the area can be selected to cast the skill, relative to Origin.
+	SelectableArea() Area
+	// CastArea is the area affected when sel is selected.
+	CastArea(sel Pos) Area
+	// Cast returns events caused by casting the skill to sel.
+	// It should not change anything by itself, the events will.
+	Cast(sel Pos) []CharacterEvent
+}
+
+// HitFrame is the name of frame events an AnimatedSkill is applied at.
+const HitFrame = "hit"
+
+// AnimatedSkill is a skill played as an action of its caster.
+// When the action has a frame named HitFrame, Stage.Cast queues events of the skill on the action,
+// and they are resolved when the frame starts, so rules are in sync with the visuals.
+// They are resolved at once while the stage is simulating.
+type AnimatedSkill interface {
+	Skill
+	// Action returns a new action of the caster casting the skill.
+	Action() *Action
+}
+
+// CharacterEvent is an event happened to a character, usually by a skill.
+type CharacterEvent struct {
+	// Character is the character the event happened to.
+	Character *Character
+	// Source is the character caused the event. It could be nil.
+	Source *Character
+	// On is name of the event. eg. "attack", "attacked"
+	On string
+	// Value is a value the Effect will use. eg. amount of damage
+	Value int
+	// Effect applies the event to the Character. It could be nil.
+	Effect func(ev *CharacterEvent)
+	// Cancelled event will not be applied.
+	Cancelled bool
+	// Reaction is an event caused by a PostEventHook.
+	Reaction bool
+	// Stage is the stage resolving the event, it is set by Stage.Resolve.
+	// Effects should use its Rand for random values.
+	Stage *Stage
+}
+
+// PreEventHook is called before an event is applied.
+// It could change or cancel the event.
+type PreEventHook func(s *Stage, ev *CharacterEvent)
+
+// PostEventHook is called after an event is applied.
+// It could cause reactions, which are applied after events caused them.
+// Reactions don't cause reactions again.
+type PostEventHook func(s *Stage, ev *CharacterEvent) []CharacterEvent
+
+// Selectable reports whether sel can be selected to cast the skill.
+// It should be in line of sight from the origin, skills cannot be cast through walls.
+func Selectable(sk Skill, sel Pos) bool {
+	o := sk.Origin()
+	if o == nil {
+		return false
+	}
+	if !sk.SelectableArea().Translate(o.Pos).Contains(sel) {
+		return false
+	}
+	return o.Board == nil || o.Board.LineOfSight(o.Pos, sel)
+}
+
+// Resolve applies the events in order, with hooks of the stage.
+// It returns events actually applied, including reactions.
+func (s *Stage) Resolve(events []CharacterEvent) []CharacterEvent {
+	queue := append([]CharacterEvent(nil), events...)
+	applied := make([]CharacterEvent, 0, len(queue))
+	for i := 0; i < len(queue); i++ {
+		ev := queue[i]
+		ev.Stage = s
+		for _, h := range s.PreEventHooks {
+			h(s, &ev)
+		}
+		if ev.Cancelled {
+			continue
+		}
+		if ev.Effect != nil {
+			s.watchHP(ev.Source, func() {
+				ev.Effect(&ev)
+			})
+		}
+		applied = append(applied, ev)
+		if ev.Reaction {
+			continue
+		}
+		for _, h := range s.PostEventHooks {
+			for _, r := range h(s, &ev) {
+				r.Reaction = true
+				queue = append(queue, r)
+			}
+		}
+	}
+	return applied
+}
@@ -0,0 +1,48 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	a, b := newTestParty(10), newTestParty(10)
+	ca, cb := a.Characters[0], b.Characters[0]
+	damage := func(ev *CharacterEvent) {
+		ev.Character.HP -= ev.Value
+	}
+	s := &Stage{Parties: []*Party{a, b}}
+	// a passive halves damage to cb.
+	s.PreEventHooks = append(s.PreEventHooks, func(s *Stage, ev *CharacterEvent) {
+		if ev.Character == cb && ev.On == "attacked" {
+			ev.Value /= 2
+		}
+	})
+	// cb counterattacks, and ca dodges any counterattack.
+	s.PostEventHooks = append(s.PostEventHooks, func(s *Stage, ev *CharacterEvent) []CharacterEvent {
+		if ev.Character != cb || ev.On != "attacked" {
+			return nil
+		}
+		return []CharacterEvent{{Character: ev.Source, Source: cb, On: "attacked", Value: 3, Effect: damage}}
+	})
+	s.PreEventHooks = append(s.PreEventHooks, func(s *Stage, ev *CharacterEvent) {
+		if ev.Character == ca && ev.Reaction {
+			ev.Cancelled = true
+		}
+	})
+	applied := s.Resolve([]CharacterEvent{
+		{Character: cb, Source: ca, On: "attacked", Value: 4, Effect: damage},
+		{Character: cb, Source: ca, On: "attacked", Value: 6, Effect: damage},
+	})
+	if cb.HP != 5 {
+		t.Fatalf("hp of attacked: want 5, got %v", cb.HP)
+	}
+	if ca.HP != 10 {
+		t.Fatalf("hp of attacker: want 10, got %v", ca.HP)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("applied events: want 2, got %v", len(applied))
+	}
+	if applied[1].Value != 3 {
+		t.Fatalf("applied event value: want 3, got %v", applied[1].Value)
+	}
+}
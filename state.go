@@ -0,0 +1,240 @@
+package tiled
+
+import (
+	"sort"
+)
+
+// State is a status effect on a character. eg. bleed, poison, haste
+type State struct {
+	Name string
+	// Unit is what the Duration counted by.
+	Unit StateUnit
+	// Duration is how long the state lasts more, in Unit.
+	// The state lasts until it is removed, if it is zero.
+	Duration int
+	// Stacks is how many times the state is stacked. It is at least 1.
+	Stacks int
+	// MaxStacks limits Stacks when Rule is StackAdd. Zero means no limit.
+	MaxStacks int
+	// Rule decides what happens when the same state is added again.
+	Rule StackRule
+	// Modifier changes stats of the character, per stack.
+	Modifier Stats
+	// Value is a value the Effect will use. eg. damage per turn
+	Value int
+	// Effect is called every Unit while the state lasts. It could be nil.
+	// Effects having no state should be registered with RegisterStateEffect to be saved.
+	Effect func(c *Character, st *State) `json:"-"`
+}
+
+// StateUnit is a unit of a state's duration.
+type StateUnit int
+
+const (
+	// PerTurn state advances when the turn of its character's party is over.
+	PerTurn = StateUnit(iota)
+	// PerTick state advances every tick of its character.
+	PerTick
+)
+
+// StackRule decides how a state is added when the character already has the state.
+type StackRule int
+
+const (
+	// StackRefresh resets duration of the state.
+	StackRefresh = StackRule(iota)
+	// StackAdd adds stacks of the state, and resets duration.
+	StackAdd
+	// StackExtend adds duration of the state.
+	StackExtend
+	// StackIgnore keeps the state as is.
+	StackIgnore
+)
+
+// Stats are stats of a character which could be modified by states.
+type Stats struct {
+	AttackPower int
+	MaxPoints   int
+	// Defense reduces damage the character takes from others, see Stage.DamageFrom.
+	Defense int
+	// Evasion is chance of the character to evade damage from others, in percent.
+	// See Stage.DamageFrom.
+	Evasion int
+}
+
+func (s Stats) Add(t Stats) Stats {
+	return Stats{
+		AttackPower: s.AttackPower + t.AttackPower,
+		MaxPoints:   s.MaxPoints + t.MaxPoints,
+		Defense:     s.Defense + t.Defense,
+		Evasion:     s.Evasion + t.Evasion,
+	}
+}
+
+// Scale multiplies every stat by n.
+func (s Stats) Scale(n int) Stats {
+	return Stats{
+		AttackPower: s.AttackPower * n,
+		MaxPoints:   s.MaxPoints * n,
+		Defense:     s.Defense * n,
+		Evasion:     s.Evasion * n,
+	}
+}
+
+// Stats returns stats of the character, modified by its equipments, states and terrain it stands on.
+func (c *Character) Stats() Stats {
+	st := Stats{
+		AttackPower: c.AttackPower,
+		MaxPoints:   c.MaxPoints,
+	}
+	for _, it := range c.Equipments {
+		st = st.Add(it.Modifier)
+	}
+	for _, s := range c.States {
+		st = st.Add(s.Modifier.Scale(s.Stacks))
+	}
+	if t := c.Tile(); t != nil && t.Base != nil {
+		st.Defense += t.Base.Defense
+		st.Evasion += t.Base.Evasion
+	}
+	return st
+}
+
+// HasState reports whether the character has the state.
+func (c *Character) HasState(name string) bool {
+	return c.States[name] != nil
+}
+
+// AddState adds the state to the character.
+// When the character already has the state, the rule of the new state decides what to do.
+// The character keeps its own copy of the state.
+func (c *Character) AddState(st State) {
+	if st.Stacks < 1 {
+		st.Stacks = 1
+	}
+	if c.States == nil {
+		c.States = make(map[string]*State)
+	}
+	old := c.States[st.Name]
+	if old == nil {
+		c.States[st.Name] = &st
+		return
+	}
+	switch st.Rule {
+	case StackRefresh:
+		old.Duration = st.Duration
+	case StackAdd:
+		old.Stacks += st.Stacks
+		if st.MaxStacks > 0 && old.Stacks > st.MaxStacks {
+			old.Stacks = st.MaxStacks
+		}
+		old.Duration = st.Duration
+	case StackExtend:
+		old.Duration += st.Duration
+	}
+}
+
+// RemoveState removes the state from the character.
+func (c *Character) RemoveState(name string) {
+	delete(c.States, name)
+}
+
+// AdvanceStates applies effects of the character's states counted by the unit,
+// and removes states those are over.
+func (c *Character) AdvanceStates(unit StateUnit) {
+	names := make([]string, 0, len(c.States))
+	for name, st := range c.States {
+		if st.Unit == unit {
+			names = append(names, name)
+		}
+	}
+	// effects could depend on each other, keep the order.
+	sort.Strings(names)
+	for _, name := range names {
+		st := c.States[name]
+		if st == nil {
+			// removed by an effect
+			continue
+		}
+		if st.Effect != nil {
+			st.Effect(c, st)
+		}
+		if st.Duration > 0 {
+			st.Duration--
+			if st.Duration == 0 {
+				delete(c.States, name)
+			}
+		}
+	}
+}
+
+func init() {
+	RegisterStateEffect("bleed", bleed)
+	RegisterStateEffect("poison", poison)
+	RegisterStateEffect("regen", regen)
+}
+
+// Bleed damages the character every turn by damage per stack.
+func Bleed(damage, turns int) State {
+	return State{
+		Name:     "bleed",
+		Unit:     PerTurn,
+		Duration: turns,
+		Rule:     StackAdd,
+		Value:    damage,
+		Effect:   bleed,
+	}
+}
+
+func bleed(c *Character, st *State) {
+	if c.Dead() {
+		return
+	}
+	c.HP -= st.Value * st.Stacks
+}
+
+// Poison damages the character every tick. Poisoning again extends it.
+func Poison(damage, ticks int) State {
+	return State{
+		Name:     "poison",
+		Unit:     PerTick,
+		Duration: ticks,
+		Rule:     StackExtend,
+		Value:    damage,
+		Effect:   poison,
+	}
+}
+
+func poison(c *Character, st *State) {
+	if c.Dead() {
+		return
+	}
+	c.HP -= st.Value
+}
+
+// Regen heals the character every turn, up to its MaxHP.
+func Regen(heal, turns int) State {
+	return State{
+		Name:     "regen",
+		Unit:     PerTurn,
+		Duration: turns,
+		Rule:     StackRefresh,
+		Value:    heal,
+		Effect:   regen,
+	}
+}
+
+func regen(c *Character, st *State) {
+	if c.Dead() {
+		return
+	}
+	c.heal(st.Value)
+}
+
+// heal heals the character by n, up to its MaxHP.
+func (c *Character) heal(n int) {
+	c.HP += n
+	if c.MaxHP > 0 && c.HP > c.MaxHP {
+		c.HP = c.MaxHP
+	}
+}
@@ -0,0 +1,80 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func TestStates(t *testing.T) {
+	a := newTestParty(20)
+	c := a.Characters[0]
+	c.AttackPower = 5
+	c.MaxPoints = 3
+	haste := State{Name: "haste", Duration: 2, Rule: StackAdd, MaxStacks: 2, Modifier: Stats{MaxPoints: 1}}
+	c.AddState(haste)
+	c.AddState(haste)
+	c.AddState(haste)
+	c.AddState(State{Name: "rage", Modifier: Stats{AttackPower: 2}})
+	st := c.Stats()
+	if st.MaxPoints != 5 || st.AttackPower != 7 {
+		t.Fatalf("stats: want {7 5}, got %v", st)
+	}
+	c.AddState(Bleed(2, 3))
+	c.AddState(Bleed(2, 1))
+	c.MaxHP = 20
+	c.AddState(Regen(1, 0))
+	// bleed: 4 damage for 1 turn, regen: 1 heal forever.
+	c.AdvanceStates(PerTurn)
+	if c.HP != 17 {
+		t.Fatalf("hp: want 17, got %v", c.HP)
+	}
+	if c.HasState("bleed") {
+		t.Fatalf("bleed should be over")
+	}
+	c.AdvanceStates(PerTurn)
+	if c.HasState("haste") || c.Stats().MaxPoints != 3 {
+		t.Fatalf("haste should be over")
+	}
+	if !c.HasState("regen") || !c.HasState("rage") || c.HP != 18 {
+		t.Fatalf("states without duration should last")
+	}
+	c.AddState(Poison(1, 2))
+	c.AddState(Poison(1, 1))
+	for i := 0; i < 5; i++ {
+		c.AdvanceStates(PerTick)
+	}
+	if c.HP != 15 || c.HasState("poison") {
+		t.Fatalf("poison: want hp 15 and over, got hp %v", c.HP)
+	}
+}
+
+func TestStatesTurnOver(t *testing.T) {
+	a, b := newTestParty(10), newTestParty(10)
+	s := &Stage{Parties: []*Party{a, b}}
+	s.Start()
+	a.Characters[0].AddState(Bleed(3, 0))
+	b.Characters[0].AddState(Bleed(3, 0))
+	s.TurnOver()
+	if a.Characters[0].HP != 7 || b.Characters[0].HP != 10 {
+		t.Fatalf("only states of the party finished its turn should advance")
+	}
+	// a bleeds 3 more times in its turns, b bleeds in its turns as well.
+	for i := 0; i < 6; i++ {
+		s.TurnOver()
+	}
+	if a.Characters[0].HP != -2 || !s.Win(b) {
+		t.Fatalf("party bled to death should lose")
+	}
+}
+
+func TestStatesDead(t *testing.T) {
+	a := newTestParty(2)
+	c := a.Characters[0]
+	c.AddState(Bleed(3, 0))
+	c.AddState(Poison(3, 0))
+	c.AdvanceStates(PerTurn)
+	c.AdvanceStates(PerTurn)
+	c.AdvanceStates(PerTick)
+	if c.HP != -1 {
+		t.Fatalf("dead character should not take damage from states: want hp -1, got %v", c.HP)
+	}
+}
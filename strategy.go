@@ -0,0 +1,311 @@
+package tiled
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Strategy decides actions of NPC characters.
+//
+// It tries every candidate of moving to a reachable tile then casting a skill
+// or attacking, or just moving. Each candidate is scored by the Evaluators
+// and the candidate with the best score is taken.
+// The strategy only knows about what the character's party can see.
+type Strategy struct {
+	// Evaluators score candidates, the scores are summed up.
+	Evaluators []Evaluator
+	// Rand breaks ties between candidates having the same score.
+	// The stage's one is used when it is nil.
+	Rand *rand.Rand
+}
+
+// NewStrategy creates a new strategy with the evaluators.
+// Strategies with the same seed act the same way in the same situation.
+func NewStrategy(seed int64, evals ...Evaluator) *Strategy {
+	return &Strategy{
+		Evaluators: evals,
+		Rand:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Evaluator scores a candidate. Higher is better.
+// It is called while the candidate is applied to the stage temporarily,
+// so it can look at the stage for the result of the candidate.
+type Evaluator interface {
+	Evaluate(s *Stage, cand *Candidate) float64
+}
+
+// Candidate is a possible action of a character in its turn.
+type Candidate struct {
+	Character *Character
+	// Path is the path the character moves through. It could be empty.
+	Path *Path
+	// Dest is the tile where the character moves to.
+	Dest *Tile
+	// Skill is cast to Target after the move.
+	// When Skill is nil and Target is not nil, the character attacks Target.
+	// When both are nil, the character only moves.
+	Skill  Skill
+	Target *Tile
+	// hp is HP of characters before the candidate is applied.
+	hp map[*Character]int
+}
+
+// HPLost returns HP the character lost by the candidate.
+func (cand *Candidate) HPLost(ch *Character) int {
+	return cand.hp[ch] - ch.HP
+}
+
+// Action lets the character act by the strategy.
+// It returns the candidate taken, or nil if the character did nothing.
+func (stg *Strategy) Action(s *Stage, c *Character) *Candidate {
+	if c.Dead() || c.Tile() == nil {
+		return nil
+	}
+	cands := stg.Candidates(s, c)
+	best := make([]*Candidate, 0)
+	bestScore := 0.0
+	for _, cand := range cands {
+		score, ok := stg.score(s, cand)
+		if !ok {
+			continue
+		}
+		if len(best) == 0 || score > bestScore {
+			best = []*Candidate{cand}
+			bestScore = score
+		} else if score == bestScore {
+			best = append(best, cand)
+		}
+	}
+	if len(best) == 0 {
+		return nil
+	}
+	cand := best[0]
+	if len(best) > 1 {
+		r := stg.Rand
+		if r == nil {
+			r = s.Rand()
+		}
+		cand = best[r.Intn(len(best))]
+	}
+	if cand.Dest != c.Tile() {
+		s.MoveTo(c, cand.Dest)
+	}
+	if cand.Skill != nil {
+		s.Cast(cand.Skill, cand.Target.Pos)
+	} else if cand.Target != nil {
+		s.Attack(c, cand.Target)
+	}
+	return cand
+}
+
+// Candidates returns every candidate of the character's action, in a stable order.
+// Skills and attacks only target tiles where an enemy can be seen now,
+// and could be seen by the character from the destination.
+func (stg *Strategy) Candidates(s *Stage, c *Character) []*Candidate {
+	hp := make(map[*Character]int)
+	for _, p := range s.Parties {
+		for _, ch := range p.Characters {
+			hp[ch] = ch.HP
+		}
+	}
+	names := make([]string, 0)
+	skills := c.skills()
+	for name := range skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	cands := make([]*Candidate, 0)
+	area, paths := c.ReachableTiles()
+	from := c.Tile()
+	for _, pos := range area.Poses() {
+		dest := from.Board.TileAt[pos]
+		cands = append(cands, &Candidate{Character: c, Path: paths[pos], Dest: dest, hp: hp})
+		for _, name := range names {
+			sk := skills[name]
+			// selectable area is relative to the origin of the skill,
+			// which is the character's tile.
+			for _, sel := range sk.SelectableArea().Translate(pos).Poses() {
+				t := from.Board.TileAt[sel]
+				if t == nil || !stg.targetable(s, c, dest, sk.CastArea(sel)) {
+					continue
+				}
+				cands = append(cands, &Candidate{Character: c, Path: paths[pos], Dest: dest, Skill: sk, Target: t, hp: hp})
+			}
+		}
+		for _, t := range c.attackableFrom(dest) {
+			if !stg.targetable(s, c, dest, CreateArea([]Pos{t.Pos})) {
+				continue
+			}
+			cands = append(cands, &Candidate{Character: c, Path: paths[pos], Dest: dest, Target: t, hp: hp})
+		}
+	}
+	return cands
+}
+
+// targetable reports whether the party of the character sees an enemy in the area,
+// which the character could see from the tile.
+func (stg *Strategy) targetable(s *Stage, c *Character, from *Tile, area Area) bool {
+	for _, pos := range area.Poses() {
+		t := c.Tile().Board.TileAt[pos]
+		if t == nil || t.Occupier == nil || t.Occupier.Dead() {
+			continue
+		}
+		if c.Party.IsHostile(t.Occupier.Party) && s.CanSee(c.Party, t) && c.seesFrom(from, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// score applies the candidate to the stage temporarily and evaluates it.
+// It returns false when the candidate cannot be applied.
+func (stg *Strategy) score(s *Stage, cand *Candidate) (float64, bool) {
+	s.simulating++
+	defer func() {
+		s.simulating--
+	}()
+	c := cand.Character
+	cmd := s.NewCommand(func() bool {
+		if cand.Dest != c.Tile() && !c.MoveTo(cand.Dest) {
+			return false
+		}
+		if cand.Skill != nil {
+			if !Selectable(cand.Skill, cand.Target.Pos) {
+				return false
+			}
+			s.cast(cand.Skill, cand.Target.Pos)
+		} else if cand.Target != nil {
+			return s.attack(c, cand.Target)
+		}
+		return true
+	})
+	if !cmd.Do() {
+		return 0, false
+	}
+	score := 0.0
+	for _, e := range stg.Evaluators {
+		score += e.Evaluate(s, cand)
+	}
+	cmd.Undo()
+	return score, true
+}
+
+// skills returns skills of the character, or of its class if it doesn't have its own.
+func (c *Character) skills() map[string]Skill {
+	if c.Skills == nil && c.Class != nil {
+		return c.Class.Skills
+	}
+	return c.Skills
+}
+
+// DamageDealt scores HP enemies lost by the candidate, minus HP others lost.
+// Each enemy killed adds KillBonus more.
+type DamageDealt struct {
+	Weight    float64
+	KillBonus float64
+}
+
+func (e DamageDealt) Evaluate(s *Stage, cand *Candidate) float64 {
+	p := cand.Character.Party
+	score := 0.0
+	for _, q := range s.Parties {
+		for _, ch := range q.Characters {
+			lost := float64(cand.HPLost(ch))
+			if !p.IsHostile(q) {
+				score -= lost
+				continue
+			}
+			score += lost
+			if ch.Dead() && cand.hp[ch] > 0 {
+				score += e.KillBonus
+			}
+		}
+	}
+	return e.Weight * score
+}
+
+// RiskTaken scores negatively by attack power of visible characters hostile to the
+// character, who could attack the destination of the candidate in their next turn.
+type RiskTaken struct {
+	Weight float64
+}
+
+func (e RiskTaken) Evaluate(s *Stage, cand *Candidate) float64 {
+	c := cand.Character
+	risk := 0.0
+	for _, q := range s.Parties {
+		if !q.IsHostile(c.Party) {
+			continue
+		}
+		for _, en := range q.Characters {
+			if en.Dead() || !s.CanSee(c.Party, en.Tile()) {
+				continue
+			}
+			if en.threatens(cand.Dest) {
+				risk += float64(en.Stats().AttackPower)
+			}
+		}
+	}
+	return -e.Weight * risk
+}
+
+// threatens reports whether the character could attack the tile in its next turn.
+func (c *Character) threatens(t *Tile) bool {
+	area, _ := c.reachable(c.Tile(), c.Stats().MaxPoints)
+	board := c.Tile().Board
+	for _, pos := range area.Poses() {
+		from := board.TileAt[pos]
+		for _, sk := range c.skills() {
+			if sk.SelectableArea().Translate(pos).Contains(t.Pos) {
+				return true
+			}
+		}
+		for _, at := range c.attackableFrom(from) {
+			if at == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DistanceToObjective scores negatively by distance from the destination of
+// the candidate to the objective.
+type DistanceToObjective struct {
+	Weight float64
+	// Objective returns the tile the character should go to.
+	// When it is nil, the objective is the nearest enemy the party can see.
+	Objective func(s *Stage, c *Character) *Tile
+}
+
+func (e DistanceToObjective) Evaluate(s *Stage, cand *Candidate) float64 {
+	c := cand.Character
+	board := cand.Dest.Board
+	if e.Objective != nil {
+		t := e.Objective(s, c)
+		if t == nil {
+			return 0
+		}
+		return -e.Weight * float64(board.Distance(cand.Dest.Pos, t.Pos))
+	}
+	nearest := -1
+	for _, q := range s.Parties {
+		if !c.Party.IsHostile(q) {
+			continue
+		}
+		for _, en := range q.Characters {
+			if en.Dead() || !s.CanSee(c.Party, en.Tile()) {
+				continue
+			}
+			d := board.Distance(cand.Dest.Pos, en.Tile().Pos)
+			if nearest < 0 || d < nearest {
+				nearest = d
+			}
+		}
+	}
+	if nearest < 0 {
+		return 0
+	}
+	return -e.Weight * float64(nearest)
+}
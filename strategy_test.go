@@ -0,0 +1,82 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func setupStrategyTest() (*Stage, *Character, *Character) {
+	b := newTestBoard([]string{
+		".....",
+		".....",
+		".....",
+	})
+	b.Line = testLine
+	a, e := newTestParty(10), newTestParty(10)
+	a.NPC = true
+	s := &Stage{Board: b, Parties: []*Party{a, e}}
+	c := a.Characters[0]
+	c.Place(b.TileAt[Pos{0, 1}])
+	c.RemainingPoints = 2
+	c.MaxPoints = 2
+	c.AttackPower = 3
+	c.AttackDirs = [][]string{{"N"}, {"E"}, {"S"}, {"W"}}
+	en := e.Characters[0]
+	en.Place(b.TileAt[Pos{3, 1}])
+	en.MaxPoints = 1
+	en.AttackPower = 5
+	en.AttackDirs = [][]string{{"E"}, {"W"}}
+	return s, c, en
+}
+
+func TestStrategyAttack(t *testing.T) {
+	s, c, en := setupStrategyTest()
+	stg := NewStrategy(1, DamageDealt{Weight: 1}, DistanceToObjective{Weight: 0.1})
+	s.DefaultStrategy = stg
+	c.Party.AutoAction(s)
+	if c.Tile().Pos != (Pos{2, 1}) {
+		t.Fatalf("position: want %v, got %v", Pos{2, 1}, c.Tile().Pos)
+	}
+	if en.HP != 7 {
+		t.Fatalf("enemy hp: want 7, got %v", en.HP)
+	}
+	if !s.Undo() || !s.Undo() || c.Tile().Pos != (Pos{0, 1}) {
+		t.Fatalf("actions of strategy should be undoable")
+	}
+}
+
+func TestStrategyRisk(t *testing.T) {
+	s, c, en := setupStrategyTest()
+	stg := NewStrategy(1, DamageDealt{Weight: 1}, RiskTaken{Weight: 1})
+	stg.Action(s, c)
+	// counterattack of the enemy hurts more than the attack.
+	if en.HP != 10 {
+		t.Fatalf("enemy hp: want 10, got %v", en.HP)
+	}
+	switch pos := c.Tile().Pos; pos {
+	case Pos{1, 1}, Pos{2, 0}, Pos{2, 1}, Pos{2, 2}:
+		t.Fatalf("position %v is threatened by the enemy", pos)
+	}
+	s, c, en = setupStrategyTest()
+	stg = NewStrategy(1, DamageDealt{Weight: 1}, RiskTaken{Weight: 0.5})
+	stg.Action(s, c)
+	if en.HP != 7 {
+		t.Fatalf("enemy hp with less risk weight: want 7, got %v", en.HP)
+	}
+}
+
+func TestStrategyFog(t *testing.T) {
+	dests := make([]Pos, 0)
+	for i := 0; i < 2; i++ {
+		s, c, en := setupStrategyTest()
+		c.Sight = 1
+		stg := NewStrategy(7, DamageDealt{Weight: 1}, DistanceToObjective{Weight: 1})
+		stg.Action(s, c)
+		if en.HP != 10 {
+			t.Fatalf("enemy out of sight should not be attacked")
+		}
+		dests = append(dests, c.Tile().Pos)
+	}
+	if dests[0] != dests[1] {
+		t.Fatalf("strategies with the same seed should act the same: got %v", dests)
+	}
+}
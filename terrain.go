@@ -0,0 +1,53 @@
+package tiled
+
+// Movement classes of characters. Terrains cost differently by them, see BaseTile.Cost.
+const (
+	Walk = "walk"
+	Swim = "swim"
+	Fly  = "fly"
+)
+
+// BaseTile is terrain of tiles. Tiles of the same terrain share a base tile. eg. grass, lake, wall
+type BaseTile struct {
+	Name string
+	// Cost is how many times of Way.Cost characters spend to enter the tile, by their movement class.
+	// A movement class not in Cost cannot enter the tile. Nil Cost means 1 for every class.
+	Cost map[string]int
+	// Impassable tile cannot be entered by any character. eg. wall
+	Impassable bool
+	// Defense and Evasion are added to stats of characters standing on the tile.
+	Defense int
+	Evasion int
+	// BlocksSight makes tiles block sight through them. eg. forest
+	BlocksSight bool
+}
+
+// CostFor returns how many times of Way.Cost the movement class spends to enter the tile,
+// and whether it can enter.
+func (b *BaseTile) CostFor(movement string) (int, bool) {
+	if b.Impassable {
+		return 0, false
+	}
+	if b.Cost == nil {
+		return 1, true
+	}
+	cost, ok := b.Cost[movement]
+	return cost, ok
+}
+
+// canStand checks whether terrain of the tile lets the character stand on it, regardless of its occupier.
+func (c *Character) canStand(t *Tile) bool {
+	if t.Base == nil {
+		return true
+	}
+	_, ok := t.Base.CostFor(c.movement())
+	return ok
+}
+
+// movement returns movement class of the character.
+func (c *Character) movement() string {
+	if c.Movement == "" {
+		return Walk
+	}
+	return c.Movement
+}
@@ -0,0 +1,75 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func TestTerrain(t *testing.T) {
+	b := newTestBoard([]string{
+		"...",
+		"...",
+	})
+	lake := &BaseTile{Name: "lake", Cost: map[string]int{Walk: 3, Swim: 1, Fly: 1}}
+	wall := &BaseTile{Name: "wall", Impassable: true, BlocksSight: true}
+	forest := &BaseTile{Name: "forest", Defense: 2, Evasion: 10}
+	b.TileAt[Pos{1, 0}].Base = lake
+	b.TileAt[Pos{1, 1}].Base = wall
+	b.TileAt[Pos{2, 1}].Base = forest
+
+	walker := &Character{RemainingPoints: 10}
+	walker.Place(b.TileAt[Pos{0, 0}])
+	if !walker.MoveTo(b.TileAt[Pos{2, 0}]) || walker.SpentPoints != 4 {
+		t.Fatalf("walk across the lake: want spent 4, got %v", walker.SpentPoints)
+	}
+	swimmer := &Character{RemainingPoints: 10, Movement: Swim}
+	swimmer.Place(b.TileAt[Pos{0, 0}])
+	if !swimmer.MoveTo(b.TileAt[Pos{1, 0}]) || swimmer.SpentPoints != 1 {
+		t.Fatalf("swim in the lake: want spent 1, got %v", swimmer.SpentPoints)
+	}
+	if swimmer.MoveTo(b.TileAt[Pos{2, 1}]) {
+		t.Fatalf("swimmer should not enter the forest not having swim cost")
+	}
+	if swimmer.Step(*swimmer.Tile().Way("S")) {
+		t.Fatalf("wall should not be entered")
+	}
+	if !b.TileAt[Pos{1, 1}].BlocksSight() || b.TileAt[Pos{1, 0}].BlocksSight() {
+		t.Fatalf("wall should block sight, and lake shouldn't")
+	}
+
+	a, e := newTestParty(10), newTestParty(10)
+	c, en := a.Characters[0], e.Characters[0]
+	c.Place(b.TileAt[Pos{0, 1}])
+	en.Place(b.TileAt[Pos{2, 1}])
+	s := &Stage{Board: b, Parties: []*Party{a, e}, CollisionDamage: 1}
+	if st := en.Stats(); st.Defense != 2 || st.Evasion != 10 {
+		t.Fatalf("stats on the forest: want defense 2 and evasion 10, got %v", st)
+	}
+	if dmg := s.DamageFrom(c, en, 5); dmg != 3 {
+		t.Fatalf("damage on the forest: want 3, got %v", dmg)
+	}
+	for i := 0; i < 20; i++ {
+		if s.DamageFrom(c, en, 5) == 0 {
+			break
+		}
+		if i == 19 {
+			t.Fatalf("damage on the forest: want to be evaded sometimes")
+		}
+	}
+	forest.Evasion = 100
+	evaded := 0
+	s.Events.Subscribe(func(ev Event) {
+		if _, ok := ev.(Evaded); ok {
+			evaded++
+		}
+	})
+	c.AttackDirs = [][]string{{"E", "E"}}
+	c.RemainingPoints = 1
+	if !s.Attack(c, en.Tile()) || en.HP != 10 || evaded != 1 {
+		t.Fatalf("attack on full evasion: want hp 10 and 1 evaded event, got %v and %v", en.HP, evaded)
+	}
+	forest.Evasion = 10
+	s.Push(en, c, "E", 1)
+	if c.Tile().Pos != (Pos{0, 1}) || c.HP != 9 {
+		t.Fatalf("push into the wall: want hp 9 at %v, got %v at %v", Pos{0, 1}, c.HP, c.Tile().Pos)
+	}
+}
@@ -1,7 +1,10 @@
 package tiled
 
 import (
-	"sort"
+	"context"
+	"math"
+	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -9,6 +12,10 @@ type Pos [2]int
 type Dir [2]int
 type Size [2]int
 
+func (p Pos) Add(q Pos) Pos {
+	return Pos{p[0] + q[0], p[1] + q[1]}
+}
+
 type World struct {
 	// Time will only passed on playing.
 	// eg. Opening an UI will stop the world.
@@ -17,22 +24,146 @@ type World struct {
 	FPS    int
 	Player *Player
 	Field  map[string]*Field
+	// Clock is the clock the world runs by. It is the wall clock when nil.
+	Clock Clock
+	// MaxCatchUp is how many ticks the world ticks at most at once to catch up the clock.
+	// Time missed more than that is dropped. When not defined, it is DefaultMaxCatchUp.
+	MaxCatchUp int
+	// Events publishes changes of the world. Subscribers are called in the loop of ListenEvent.
+	Events Bus
+
+	mu        sync.Mutex
+	paused    bool
+	resumedAt time.Time
+	timeScale float64
 }
 
-func (w *World) ListenEvent() {
+// DefaultMaxCatchUp is MaxCatchUp of a world not defining it.
+const DefaultMaxCatchUp = 5
+
+// ListenEvent runs the world until ctx is done, and returns ctx's error.
+//
+// Characters on the player's field tick at fixed steps of 1/FPS second of the
+// world's time. When the world couldn't keep up with the clock for a while,
+// it ticks as many times as it missed to catch up, up to MaxCatchUp.
+func (w *World) ListenEvent(ctx context.Context) error {
 	if w.FPS <= 0 {
 		w.FPS = 1
 	}
-	d := time.Duration(time.Second / w.FPS)
-	ticker := time.NewTicker(d)
+	clock := w.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	d := time.Second / time.Duration(w.FPS)
+	catchUp := w.MaxCatchUp
+	if catchUp <= 0 {
+		catchUp = DefaultMaxCatchUp
+	}
+	last := clock.Now()
+	var lag time.Duration
 	for {
+		// wake up when the next tick is due.
+		wait := time.Duration(float64(d-lag) / w.scale())
 		select {
-		case t := <-ticker.C:
-			for _, t := range w.Player.Field.Board.Tiles {
-				Tile.Occupier.Tick(d)
-			}
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(wait):
+		}
+		now := clock.Now()
+		w.mu.Lock()
+		paused := w.paused
+		if w.resumedAt.After(last) {
+			// time passed while paused doesn't count.
+			last = w.resumedAt
+		}
+		w.mu.Unlock()
+		if paused {
+			last = now
+			continue
 		}
+		lag += time.Duration(float64(now.Sub(last)) * w.scale())
+		last = now
+		// a long stall, eg. sleep of the machine, should not freeze the world to catch up.
+		if max := time.Duration(catchUp) * d; lag > max {
+			lag = max
+		}
+		for lag >= d {
+			w.tick(d)
+			lag -= d
+		}
+	}
+}
+
+// tick passes time of the world by d.
+func (w *World) tick(d time.Duration) {
+	w.Time = w.Time.Add(d)
+	if w.Player == nil || w.Player.Field == nil {
+		return
+	}
+	f := w.Player.Field
+	if f.PC != nil {
+		w.tickCharacter(f.PC, d)
 	}
+	for _, c := range f.NPCs {
+		w.tickCharacter(c, d)
+	}
+	w.Events.Publish(Ticked{Time: w.Time, Delta: d})
+}
+
+// tickCharacter ticks the character by d, publishing changes of its HP by its states.
+func (w *World) tickCharacter(c *Character, d time.Duration) {
+	hp := c.HP
+	c.AdvanceStates(PerTick)
+	for _, ev := range hpEvents(c, nil, hp) {
+		w.Events.Publish(ev)
+	}
+	c.play(d)
+}
+
+// Pause stops time of the world, until Resume is called.
+func (w *World) Pause() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = true
+}
+
+// Resume lets time of the world pass again.
+func (w *World) Resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.paused {
+		return
+	}
+	w.paused = false
+	clock := w.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	w.resumedAt = clock.Now()
+}
+
+// Paused reports whether the world is paused.
+func (w *World) Paused() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.paused
+}
+
+func (w *World) scale() float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timeScale <= 0 {
+		return 1
+	}
+	return w.timeScale
+}
+
+// SetTimeScale sets how fast time of the world passes compared to the clock.
+// eg. 2 makes the world twice faster. Zero or less is treated as 1.
+func (w *World) SetTimeScale(scale float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timeScale = scale
 }
 
 type Field struct {
@@ -49,60 +180,361 @@ type Stage struct {
 	// ActiveParty is the party currently acting, either by user or AI.
 	ActiveParty *Party
 	// WaitedParties will act before the next party of ActiveParty.
+	// They are not used with Timeline.
 	WaitedParties []*Party
-	// Allies are ally parties to Party.
-	Allies map[*Party][]*Party
+	// Timeline decides order of characters instead of party rotation, when it is not nil.
+	Timeline *Timeline
+	// ActiveCharacter is the character currently acting with Timeline.
+	// ActiveParty is its party. It is nil without Timeline.
+	ActiveCharacter *Character
 	// DefaultStrategy is the default AI strategy for NPC.
 	// It should be defined so it can be used when an NPC doesn't have distinctive strategy.
 	DefaultStrategy *Strategy
+	// Conditions are victory conditions of each party.
+	// A party without conditions should eliminate all enemies to win.
+	Conditions map[*Party][]VictoryCondition
+	// MaxTurns limits turns of the stage. Zero means no limit.
+	MaxTurns int
+	// FlankDamage and BackDamage multiply damage attacking a character
+	// from its flank or back. Zero means 1.
+	FlankDamage float64
+	BackDamage  float64
+	// CollisionDamage is damage characters take when forced movement is blocked.
+	CollisionDamage int
+	// turn is the current turn, it starts from 1 when the stage started.
+	turn int
+	// rotation is the party having turn in order of Parties.
+	// It differs from ActiveParty while waited parties are acting.
+	rotation *Party
+	// PreEventHooks and PostEventHooks are called for every event resolved in the stage.
+	// They are how reactions and passive skills work.
+	PreEventHooks  []PreEventHook
+	PostEventHooks []PostEventHook
+	// Seed is the seed of random numbers in the stage.
+	// Stages with the same seed and inputs end up the same, see Replay.
+	Seed int64
+	// rand is the random number generator of the stage, seeded by Seed.
+	// src is its source, counting numbers drawn so a saved stage could go on from there.
+	rand *rand.Rand
+	src  *countedSource
+	// seen is area each party has seen in the stage.
+	seen map[*Party]Area
+	// Events publishes changes of the stage.
+	Events Bus
+	// simulating is greater than zero while changes of the stage are not real. eg. AI's simulation
+	simulating int
+	// watching is true while changes of HP are watched to be published.
+	watching bool
+	// history is commands done after the last commit.
+	history []Command
+	// undone is commands undone, they could be redone.
+	undone []Command
+}
+
+// Start starts the first turn of the stage, the first party acts first.
+// With Timeline, the first character in the timeline acts first.
+func (s *Stage) Start() {
+	s.seed(s.Seed, 0)
+	s.turn = 1
+	s.rotation = nil
+	s.WaitedParties = nil
+	s.ActiveParty = nil
+	s.ActiveCharacter = nil
+	if s.Timeline != nil {
+		s.Timeline.reset()
+		s.nextCharacter()
+	} else {
+		s.nextParty()
+	}
+	s.publishTurn()
+}
+
+// Rand returns the random number generator of the stage.
+// Everything random in the stage should use it to be reproducible.
+func (s *Stage) Rand() *rand.Rand {
+	if s.rand == nil {
+		s.seed(s.Seed, 0)
+	}
+	return s.rand
+}
+
+// seed seeds random numbers of the stage, then skips n numbers.
+func (s *Stage) seed(seed, n int64) {
+	s.src = &countedSource{src: rand.NewSource(seed).(rand.Source64), seed: seed}
+	for i := int64(0); i < n; i++ {
+		s.src.Uint64()
+	}
+	s.rand = rand.New(s.src)
+}
+
+// countedSource is a source of random numbers counting numbers drawn from it.
+type countedSource struct {
+	src  rand.Source64
+	seed int64
+	n    int64
+}
+
+func (s *countedSource) Int63() int64 {
+	s.n++
+	return s.src.Int63()
+}
+
+func (s *countedSource) Uint64() uint64 {
+	s.n++
+	return s.src.Uint64()
+}
+
+func (s *countedSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed = seed
+	s.n = 0
+}
+
+// CurrentTurn returns the current turn. It is 0 before the stage started.
+// A turn is over when every party acted once.
+func (s *Stage) CurrentTurn() int {
+	return s.turn
+}
+
+// Win reports whether the party achieved any of its victory conditions.
+func (s *Stage) Win(p *Party) bool {
+	for _, c := range s.conditions(p) {
+		if c.Achieved(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Defeated reports whether the party cannot win the stage anymore.
+// It happens when every character of the party is dead,
+// or the party failed any of its conditions, or an enemy party won.
+func (s *Stage) Defeated(p *Party) bool {
+	if p.Eliminated() {
+		return true
+	}
+	for _, c := range s.conditions(p) {
+		if c.Failed(s, p) {
+			return true
+		}
+	}
+	for _, q := range s.Parties {
+		if !p.IsHostile(q) {
+			continue
+		}
+		if s.Win(q) {
+			return true
+		}
+	}
+	return false
+}
+
+// NoMorePlayer reports whether every party controlled by players is defeated.
+// It is always false for a stage only NPC parties are in.
+func (s *Stage) NoMorePlayer() bool {
+	player := false
+	for _, p := range s.Parties {
+		if p.NPC {
+			continue
+		}
+		player = true
+		if !s.Defeated(p) {
+			return false
+		}
+	}
+	return player
 }
 
-func Win(*Player) bool      {}
-func Defeated(*Player) bool {}
-func NoMorePlayer() bool    {}
-func CurrentTurn() int      {}
-func MaxTurns() int         {}
+// Over reports whether the stage is over.
+func (s *Stage) Over() bool {
+	if s.MaxTurns > 0 && s.turn > s.MaxTurns {
+		return true
+	}
+	if s.NoMorePlayer() {
+		return true
+	}
+	for _, p := range s.Parties {
+		if s.Win(p) {
+			return true
+		}
+	}
+	return false
+}
 
+func (s *Stage) conditions(p *Party) []VictoryCondition {
+	conds := s.Conditions[p]
+	if len(conds) == 0 {
+		return []VictoryCondition{EliminateEnemies{}}
+	}
+	return conds
+}
+
+// TurnOver passes the turn to the next party.
+// Waited parties act first, then next party of Parties which is not defeated yet.
+// The stage goes to the next turn after the last party acted.
+// States of characters in the party finished its turn advance,
+// and they are done, so their points are refilled for the next turn.
+// Actions done so far are committed, so they cannot be undone anymore.
+//
+// With Timeline, it passes the turn to the next character in the timeline instead.
+// Only the character finished its turn advances its states and is done.
 func (s *Stage) TurnOver() {
-	if len(s.WaitedParties) != 0 {
-		w.ActiveParty = w.WaitedParties[0]
-		w.WaitedParty = w.WaitedParty[1:]
+	var done []*Character
+	if s.ActiveCharacter != nil {
+		done = []*Character{s.ActiveCharacter}
+	} else if s.ActiveParty != nil {
+		done = s.ActiveParty.Characters
+	}
+	s.watchHP(nil, func() {
+		for _, c := range done {
+			c.AdvanceStates(PerTurn)
+		}
+	})
+	for _, c := range done {
+		c.Done()
+	}
+	s.Commit()
+	for _, p := range s.Parties {
+		s.remember(p)
+	}
+	if s.turn == 0 {
+		s.Start()
+		return
+	}
+	if s.Timeline != nil {
+		s.nextCharacter()
+	} else if len(s.WaitedParties) != 0 {
+		s.ActiveParty = s.WaitedParties[0]
+		s.WaitedParties = s.WaitedParties[1:]
 	} else {
-		nextPartyIdx := -1
-		for i, p := range Parties {
-			if p == w.ActiveParty {
-				nextPartyIdx = i + 1
-				break
-			}
+		s.nextParty()
+	}
+	s.publishTurn()
+}
+
+func (s *Stage) nextParty() {
+	if len(s.Parties) == 0 {
+		return
+	}
+	idx := -1
+	for i, p := range s.Parties {
+		if p == s.rotation {
+			idx = i
+			break
+		}
+	}
+	for range s.Parties {
+		idx++
+		if idx == len(s.Parties) {
+			idx = 0
+			s.turn++
 		}
-		if nextPartyIdx == len(w.Parties) {
-			nextPartyIdx = 0
+		if !s.Defeated(s.Parties[idx]) {
+			break
 		}
-		w.ActiveParty = w.Parties[nextPartyIdx]
 	}
+	s.rotation = s.Parties[idx]
+	s.ActiveParty = s.rotation
 }
 
 type Board struct {
+	// Kind is kind of the board, see RegisterBoard. eg. "quad"
+	Kind   string
 	Width  int
 	Height int
-	Ways   []Dir
+	// Ways are names of ways a tile in the board could have.
+	// They are in the order of Rotate steps, starting from north.
+	Ways   []string
 	TileAt map[Pos]*Tile
+	// CornerCut decides whether characters could pass ways slipping between blocked tiles.
+	// See Way.Corners.
+	CornerCut CornerCut
+	// Distance returns least number of steps between two positions.
+	// It is used as a heuristic of path finding, so it should never overestimate.
+	Distance func(a, b Pos) int
+	// Rotate rotates a relative position around {0, 0} by n steps clockwise.
+	// Steps are different by the board, see Area.Rotate.
+	Rotate func(p Pos, n int) Pos
+	// Mirror flips a relative position horizontally around {0, 0}.
+	Mirror func(p Pos) Pos
+	// Line returns positions on the straight line from a to b, including both.
+	// Sight travels along the line.
+	Line func(a, b Pos) []Pos
+	// Offset returns the offset from a position to its neighbor through the way named dir.
+	Offset func(dir string) Pos
+}
+
+// RotationToward returns steps for Rotate, to aim an area facing north
+// toward the position to, from the position from.
+// North is the direction of the first of Ways, when the board defines Offset.
+func (b *Board) RotationToward(from, to Pos) int {
+	d := Pos{to[0] - from[0], to[1] - from[1]}
+	if d == (Pos{}) {
+		return 0
+	}
+	// {0, -2} is a valid north position for both quad and hex boards.
+	north := Pos{0, -2}
+	if b.Offset != nil && len(b.Ways) != 0 {
+		// doubled, to be rotated on boards rotating only even positions.
+		o := b.Offset(b.Ways[0])
+		north = Pos{2 * o[0], 2 * o[1]}
+	}
+	best := 0
+	bestCos := -2.0
+	for n := 0; ; n++ {
+		r := b.Rotate(north, n)
+		if n != 0 && r == north {
+			break
+		}
+		cos := float64(r[0]*d[0]+r[1]*d[1]) / (math.Hypot(float64(r[0]), float64(r[1])) * math.Hypot(float64(d[0]), float64(d[1])))
+		if cos > bestCos+1e-9 {
+			best = n
+			bestCos = cos
+		}
+	}
+	return best
 }
 
 type Tile struct {
 	Pos      Pos
+	Board    *Board
 	Base     *BaseTile
 	Occupier *Character
 	Ways     []*Way
+	// Opaque tile blocks sight through it. eg. wall
+	Opaque bool
+	// Hazard is name of the hazard on the tile. eg. "lava"
+	// Characters forced into the tile trigger a "hazard" event.
+	Hazard string
 }
 
 type Way struct {
 	Name string
 	From *Tile
 	To   *Tile
+	// Cost is cost of the way on plain terrain.
+	// Characters spend it scaled by terrain of To, see Character.WayCost.
 	Cost int
+	// Ledge way drops down to the tile. It can only be passed by forced movement.
+	Ledge bool
+	// Corners are positions beside the way, which it slips between.
+	// eg. orthogonal neighbors beside a diagonal way
+	// A position without a tile, like a hole in the board, is a blocked corner.
+	Corners []Pos
 }
 
+// CornerCut is a policy of passing ways slipping between tiles blocked by others.
+type CornerCut int
+
+const (
+	// CutCorners lets characters pass ways regardless of their corners.
+	CutCorners CornerCut = iota
+	// CutOneCorner lets characters pass ways at most one of whose corners is blocked.
+	CutOneCorner
+	// NoCornerCut lets characters pass ways only when none of their corners is blocked.
+	NoCornerCut
+)
+
 func (t *Tile) Way(name string) *Way {
 	for _, w := range t.Ways {
 		if w.Name == name {
@@ -111,52 +543,3 @@ func (t *Tile) Way(name string) *Way {
 	}
 	return nil
 }
-
-// Area is composed with Poses in tiled world.
-// Adding an existing Pos do nothing to the area.
-// Removing a non-existing Pos do nothing as well.
-type Area struct {
-	pos map[Pos]bool
-}
-
-func CreateArea(poses []Pos) Area {
-	a := &Area{}
-	for _, p := range poses {
-		a.pos[m] = true
-	}
-	return a
-}
-
-func (a Area) Add(poses []Pos) Area {
-	for _, p := range poses {
-		a.pos[m] = true
-	}
-	return a
-}
-
-func (a Area) Sub(poses []Pos) Area {
-	for _, p := range poses {
-		delete(a.pos, p)
-	}
-	return a
-}
-
-func (a Area) Poses() []Pos {
-	poses := make([]Pos, 0, len(a.pos))
-	for _, p := range a.pos {
-		poses = append(poses, p)
-	}
-	sort.Slice(poses, func(i, j int) bool {
-		if poses[i][0] < poses[j][0] {
-			return true
-		}
-		if poses[i][0] > poses[j][0] {
-			return false
-		}
-		if poses[i][1] < poses[j][1] {
-			return true
-		}
-		return false
-	})
-	return poses
-}
@@ -0,0 +1,146 @@
+package tiled
+
+// DefaultFullCT is the charge time a character needs to act, when Timeline.Full is not defined.
+const DefaultFullCT = 100
+
+// Timeline decides order of characters by their charge time (CT), instead of party rotation.
+//
+// CT of every character charges by its Speed, at least 1, at each tick of the timeline.
+// A character acts when its CT reaches Full, then its CT is reduced by Full.
+// When more than one character is full, the one with higher CT acts first,
+// then the one in front of Stage.Parties.
+//
+// A turn of a stage using a timeline is over when a character acted in the turn acts again.
+type Timeline struct {
+	// Full is the CT a character needs to act.
+	Full int
+	// ct is charge time of each character.
+	ct map[*Character]int
+	// acted is characters acted in the current turn.
+	acted map[*Character]bool
+}
+
+// NewTimeline creates a new timeline. Characters need full CT to act.
+func NewTimeline(full int) *Timeline {
+	return &Timeline{Full: full}
+}
+
+func (tl *Timeline) full() int {
+	if tl.Full <= 0 {
+		return DefaultFullCT
+	}
+	return tl.Full
+}
+
+// CT returns charge time of the character.
+func (tl *Timeline) CT(c *Character) int {
+	return tl.ct[c]
+}
+
+// reset resets CT of every character.
+func (tl *Timeline) reset() {
+	tl.ct = make(map[*Character]int)
+	tl.acted = make(map[*Character]bool)
+}
+
+// next returns the next character to act in the stage, charging CT in ct.
+// It returns nil if no one can act.
+func (tl *Timeline) next(s *Stage, ct map[*Character]int) *Character {
+	full := tl.full()
+	chars := make([]*Character, 0)
+	for _, p := range s.Parties {
+		if s.Defeated(p) {
+			continue
+		}
+		for _, c := range p.Characters {
+			if !c.Dead() {
+				chars = append(chars, c)
+			}
+		}
+	}
+	if len(chars) == 0 {
+		return nil
+	}
+	for {
+		var best *Character
+		for _, c := range chars {
+			if ct[c] >= full && (best == nil || ct[c] > ct[best]) {
+				best = c
+			}
+		}
+		if best != nil {
+			ct[best] -= full
+			return best
+		}
+		// skip ticks no one gets full.
+		ticks := -1
+		for _, c := range chars {
+			sp := c.speed()
+			t := (full - ct[c] + sp - 1) / sp
+			if ticks < 0 || t < ticks {
+				ticks = t
+			}
+		}
+		for _, c := range chars {
+			ct[c] += ticks * c.speed()
+		}
+	}
+}
+
+// speed returns how fast CT of the character charges. It is at least 1,
+// so everyone acts sooner or later.
+func (c *Character) speed() int {
+	if c.Speed < 1 {
+		return 1
+	}
+	return c.Speed
+}
+
+// Preview returns next n characters to act after the active one, without changing the timeline.
+// It could return less than n characters, if no one can act.
+func (tl *Timeline) Preview(s *Stage, n int) []*Character {
+	ct := make(map[*Character]int, len(tl.ct))
+	for c, v := range tl.ct {
+		ct[c] = v
+	}
+	chars := make([]*Character, 0, n)
+	for i := 0; i < n; i++ {
+		c := tl.next(s, ct)
+		if c == nil {
+			break
+		}
+		chars = append(chars, c)
+	}
+	return chars
+}
+
+// Delay pushes the character later in the timeline by reducing its CT, as a command.
+func (s *Stage) Delay(c *Character, ct int) bool {
+	if s.Timeline == nil || s.Timeline.ct == nil || ct <= 0 {
+		return false
+	}
+	return s.Do(s.NewCommand(func() bool {
+		s.Timeline.ct[c] -= ct
+		return true
+	}))
+}
+
+// nextCharacter lets the next character in the timeline act.
+func (s *Stage) nextCharacter() {
+	tl := s.Timeline
+	if tl.ct == nil {
+		tl.reset()
+	}
+	c := tl.next(s, tl.ct)
+	s.ActiveCharacter = c
+	if c == nil {
+		s.ActiveParty = nil
+		return
+	}
+	if tl.acted[c] {
+		s.turn++
+		tl.acted = make(map[*Character]bool)
+	}
+	tl.acted[c] = true
+	s.ActiveParty = c.Party
+}
@@ -0,0 +1,89 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func TestTimeline(t *testing.T) {
+	a, b := newTestParty(10, 10), newTestParty(10)
+	fast, slow, mid := a.Characters[0], a.Characters[1], b.Characters[0]
+	fast.Speed, slow.Speed, mid.Speed = 50, 20, 25
+	s := &Stage{Parties: []*Party{a, b}, Timeline: NewTimeline(100)}
+	s.Start()
+	// fast acts at tick 2, 4, 6, 8, mid at 4, 8 and slow at 5.
+	// a turn is over when fast acts again.
+	want := []*Character{fast, fast, mid, slow, fast, fast, mid}
+	turns := []int{1, 2, 2, 2, 3, 4, 4}
+	if s.ActiveCharacter != fast || s.ActiveParty != a {
+		t.Fatalf("start: want the fastest character")
+	}
+	name := map[*Character]string{fast: "fast", slow: "slow", mid: "mid"}
+	preview := s.Timeline.Preview(s, 6)
+	for i, c := range preview {
+		if c != want[i+1] {
+			t.Fatalf("preview %v: want %v, got %v", i, name[want[i+1]], name[c])
+		}
+	}
+	for i := range want {
+		if s.ActiveCharacter != want[i] || s.CurrentTurn() != turns[i] {
+			t.Fatalf("actor %v: want %v at turn %v, got %v at turn %v", i, name[want[i]], turns[i], name[s.ActiveCharacter], s.CurrentTurn())
+		}
+		s.TurnOver()
+	}
+}
+
+func TestTimelineDelay(t *testing.T) {
+	a, b := newTestParty(10), newTestParty(10)
+	c, d := a.Characters[0], b.Characters[0]
+	c.Speed, d.Speed = 30, 25
+	s := &Stage{Parties: []*Party{a, b}, Timeline: NewTimeline(100)}
+	s.Start()
+	if s.ActiveCharacter != c {
+		t.Fatalf("start: want the faster character")
+	}
+	if next := s.Timeline.Preview(s, 1); next[0] != d {
+		t.Fatalf("preview: want the slower character")
+	}
+	if !s.Delay(d, 80) {
+		t.Fatalf("delay: want true, got false")
+	}
+	next := s.Timeline.Preview(s, 2)
+	if next[0] != c || next[1] != d {
+		t.Fatalf("delayed character should act later")
+	}
+	s.Undo()
+	if next := s.Timeline.Preview(s, 1); next[0] != d {
+		t.Fatalf("delay should be undone")
+	}
+	// dead characters don't act.
+	d.HP = 0
+	s.TurnOver()
+	if s.ActiveCharacter != c {
+		t.Fatalf("dead character should not act")
+	}
+}
+
+func TestTimelineZeroSpeed(t *testing.T) {
+	a, b := newTestParty(10), newTestParty(10)
+	c, d := a.Characters[0], b.Characters[0]
+	d.Speed = 50
+	s := &Stage{Parties: []*Party{a, b}, Timeline: NewTimeline(100), MaxTurns: 3}
+	s.Start()
+	if s.ActiveCharacter != d {
+		t.Fatalf("start: want the faster character")
+	}
+	d.Speed = 0
+	acted := false
+	for i := 0; i < 10 && !s.Over(); i++ {
+		s.TurnOver()
+		if s.ActiveCharacter == c {
+			acted = true
+		}
+	}
+	if !s.Over() {
+		t.Fatalf("stage of characters without speed should go on to its end")
+	}
+	if !acted {
+		t.Fatalf("character without speed should act")
+	}
+}
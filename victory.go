@@ -0,0 +1,74 @@
+package tiled
+
+// VictoryCondition decides whether a party won or lost a stage.
+type VictoryCondition interface {
+	// Achieved reports whether the party achieved the condition.
+	Achieved(s *Stage, p *Party) bool
+	// Failed reports whether the party cannot achieve the condition anymore.
+	Failed(s *Stage, p *Party) bool
+}
+
+// EliminateEnemies is achieved when every character of enemy parties is dead.
+type EliminateEnemies struct{}
+
+func (EliminateEnemies) Achieved(s *Stage, p *Party) bool {
+	for _, q := range s.Parties {
+		if !p.IsHostile(q) {
+			continue
+		}
+		if !q.Eliminated() {
+			return false
+		}
+	}
+	return true
+}
+
+func (EliminateEnemies) Failed(s *Stage, p *Party) bool {
+	return false
+}
+
+// SurviveTurns is achieved when the party survived for the Turns.
+type SurviveTurns struct {
+	Turns int
+}
+
+func (c SurviveTurns) Achieved(s *Stage, p *Party) bool {
+	return s.CurrentTurn() > c.Turns
+}
+
+func (c SurviveTurns) Failed(s *Stage, p *Party) bool {
+	return false
+}
+
+// ReachTile is achieved when a character of the party is on the Tile.
+// When Character is defined, only the character should reach the tile.
+type ReachTile struct {
+	Tile      *Tile
+	Character *Character
+}
+
+func (c ReachTile) Achieved(s *Stage, p *Party) bool {
+	ch := c.Tile.Occupier
+	if ch == nil || ch.Party != p || ch.Dead() {
+		return false
+	}
+	return c.Character == nil || c.Character == ch
+}
+
+func (c ReachTile) Failed(s *Stage, p *Party) bool {
+	return c.Character != nil && c.Character.Dead()
+}
+
+// ProtectCharacter fails when the Character is dead.
+// It is never achieved by itself, so it should be used with another condition.
+type ProtectCharacter struct {
+	Character *Character
+}
+
+func (c ProtectCharacter) Achieved(s *Stage, p *Party) bool {
+	return false
+}
+
+func (c ProtectCharacter) Failed(s *Stage, p *Party) bool {
+	return c.Character.Dead()
+}
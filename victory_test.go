@@ -0,0 +1,112 @@
+package tiled
+
+import (
+	"testing"
+)
+
+func newTestParty(hps ...int) *Party {
+	p := &Party{}
+	for _, hp := range hps {
+		p.Characters = append(p.Characters, &Character{Party: p, HP: hp})
+	}
+	return p
+}
+
+func TestTurnOver(t *testing.T) {
+	a, b, c := newTestParty(1), newTestParty(1), newTestParty(1)
+	s := &Stage{Parties: []*Party{a, b, c}}
+	s.Start()
+	if s.ActiveParty != a || s.CurrentTurn() != 1 {
+		t.Fatalf("start: want party 0 at turn 1, got %v at turn %v", s.ActiveParty, s.CurrentTurn())
+	}
+	s.TurnOver()
+	s.WaitedParties = []*Party{a}
+	s.TurnOver()
+	if s.ActiveParty != a {
+		t.Fatalf("waited party should act first")
+	}
+	s.TurnOver()
+	if s.ActiveParty != c || s.CurrentTurn() != 1 {
+		t.Fatalf("after waited party: want party 2 at turn 1")
+	}
+	b.Characters[0].HP = 0
+	s.TurnOver()
+	s.TurnOver()
+	if s.ActiveParty != c || s.CurrentTurn() != 2 {
+		t.Fatalf("defeated party should be skipped: want party 2 at turn 2, got turn %v", s.CurrentTurn())
+	}
+}
+
+func TestVictoryConditions(t *testing.T) {
+	a, b := newTestParty(3, 3), newTestParty(3)
+	a.NPC = true
+	tile := &Tile{}
+	s := &Stage{
+		Parties: []*Party{a, b},
+		Conditions: map[*Party][]VictoryCondition{
+			b: {SurviveTurns{Turns: 2}, ReachTile{Tile: tile}},
+		},
+		MaxTurns: 5,
+	}
+	s.Start()
+	if s.Over() {
+		t.Fatalf("stage should not be over at start")
+	}
+	tile.Occupier = a.Characters[0]
+	if s.Win(b) {
+		t.Fatalf("enemy reaching the tile should not make a win")
+	}
+	tile.Occupier = b.Characters[0]
+	if !s.Win(b) || !s.Defeated(a) || !s.Over() {
+		t.Fatalf("reaching the tile should make a win")
+	}
+	tile.Occupier = nil
+	for i := 0; i < 4; i++ {
+		s.TurnOver()
+	}
+	if !s.Win(b) {
+		t.Fatalf("surviving 2 turns should make a win, current turn %v", s.CurrentTurn())
+	}
+	s.Conditions[b] = nil
+	a.Characters[0].HP = 0
+	if s.Win(b) {
+		t.Fatalf("eliminating part of enemies should not make a win")
+	}
+	a.Characters[1].HP = 0
+	if !s.Win(b) || !s.Defeated(a) {
+		t.Fatalf("eliminating enemies should make a win")
+	}
+	s.Conditions[b] = []VictoryCondition{SurviveTurns{Turns: 10}, ProtectCharacter{Character: b.Characters[0]}}
+	b.Characters[0].HP = 0
+	if !s.Defeated(b) || !s.NoMorePlayer() {
+		t.Fatalf("losing the protected character should make a defeat")
+	}
+}
+
+func TestTurnOverPoints(t *testing.T) {
+	b := newTestBoard([]string{"......"})
+	a, e := newTestParty(1), newTestParty(1)
+	c, en := a.Characters[0], e.Characters[0]
+	c.MaxPoints, c.RemainingPoints = 2, 2
+	en.MaxPoints, en.RemainingPoints = 2, 2
+	c.Place(b.TileAt[Pos{0, 0}])
+	en.Place(b.TileAt[Pos{5, 0}])
+	s := &Stage{Board: b, Parties: []*Party{a, e}}
+	s.Start()
+	for turn := 1; turn <= 2; turn++ {
+		if !s.Step(c, *c.Tile().Way("E")) {
+			t.Fatalf("turn %v: should step with refilled points", turn)
+		}
+		s.TurnOver()
+		if c.RemainingPoints != 2 || c.SpentPoints != 0 {
+			t.Fatalf("turn %v: points should be refilled at the end of the turn, got %v remaining and %v spent", turn, c.RemainingPoints, c.SpentPoints)
+		}
+		if !s.Step(en, *en.Tile().Way("W")) {
+			t.Fatalf("turn %v: enemy should step with refilled points", turn)
+		}
+		s.TurnOver()
+		if en.RemainingPoints != 2 || en.SpentPoints != 0 {
+			t.Fatalf("turn %v: points of the enemy should be refilled at the end of its turn", turn)
+		}
+	}
+}
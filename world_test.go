@@ -0,0 +1,91 @@
+package tiled
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestListenEvent(t *testing.T) {
+	clock := NewManualClock(time.Time{})
+	pc := &Character{HP: 20}
+	pc.AddState(Poison(1, 0))
+	w := &World{
+		FPS:        10,
+		MaxCatchUp: 4,
+		Clock:      clock,
+		Player:     &Player{Field: &Field{PC: pc}},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- w.ListenEvent(ctx)
+	}()
+	// advance waits for the world to tick for the time.
+	advance := func(d time.Duration) {
+		clock.BlockUntil(1)
+		clock.Advance(d)
+		clock.BlockUntil(1)
+	}
+	check := func(name string, elapsed time.Duration, hp int) {
+		if got := w.Time.Sub(time.Time{}); got != elapsed {
+			t.Fatalf("%v: want elapsed %v, got %v", name, elapsed, got)
+		}
+		if pc.HP != hp {
+			t.Fatalf("%v: want hp %v, got %v", name, hp, pc.HP)
+		}
+	}
+	advance(100 * time.Millisecond)
+	check("tick", 100*time.Millisecond, 19)
+	// stall
+	advance(350 * time.Millisecond)
+	check("catch up", 400*time.Millisecond, 16)
+	advance(50 * time.Millisecond)
+	check("lag", 500*time.Millisecond, 15)
+
+	w.Pause()
+	advance(time.Second)
+	check("pause", 500*time.Millisecond, 15)
+	w.Resume()
+	advance(100 * time.Millisecond)
+	check("resume", 600*time.Millisecond, 14)
+
+	w.SetTimeScale(2)
+	advance(100 * time.Millisecond)
+	check("time scale", 800*time.Millisecond, 12)
+
+	// long stall catches up only MaxCatchUp ticks.
+	advance(time.Hour)
+	check("max catch up", 1200*time.Millisecond, 8)
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("cancel: want %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestWorldTickEvents(t *testing.T) {
+	pc := &Character{HP: 2}
+	pc.AddState(Poison(1, 0))
+	w := &World{Player: &Player{Field: &Field{PC: pc}}}
+	events := make([]Event, 0)
+	w.Events.Subscribe(func(ev Event) {
+		events = append(events, ev)
+	})
+	d := 100 * time.Millisecond
+	w.tick(d)
+	w.tick(d)
+	w.tick(d)
+	want := []Event{
+		Damaged{Character: pc, Amount: 1},
+		Ticked{Time: time.Time{}.Add(d), Delta: d},
+		Damaged{Character: pc, Amount: 1},
+		Died{Character: pc},
+		Ticked{Time: time.Time{}.Add(2 * d), Delta: d},
+		Ticked{Time: time.Time{}.Add(3 * d), Delta: d},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events: want %v, got %v", want, events)
+	}
+}